@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/metal-toolbox/flasher/internal/app"
+	"github.com/metal-toolbox/flasher/internal/device"
+	"github.com/metal-toolbox/flasher/internal/model"
+	"github.com/metal-toolbox/flasher/internal/outofband"
+	"github.com/metal-toolbox/flasher/internal/redact"
+	rtypes "github.com/metal-toolbox/rivets/v2/types"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var cmdBMC = &cobra.Command{
+	Use:   "bmc",
+	Short: "BMC utility commands - power, reset, firmware install steps",
+}
+
+var cmdBMCPower = &cobra.Command{
+	Use:       "power status|on|off|cycle",
+	Short:     "Query or set the host power state",
+	Args:      cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+	ValidArgs: []string{"status", "on", "off", "cycle"},
+	Run: func(cmd *cobra.Command, args []string) {
+		runBMC(cmd.Context(), func(ctx context.Context, queryor device.OutofbandQueryor) error {
+			if args[0] != "status" {
+				if err := queryor.SetPowerState(ctx, args[0]); err != nil {
+					return err
+				}
+			}
+
+			state, err := queryor.PowerStatus(ctx)
+			if err != nil {
+				return err
+			}
+
+			fmt.Println(state)
+
+			return nil
+		})
+	},
+}
+
+var cmdBMCReset = &cobra.Command{
+	Use:   "reset",
+	Short: "Reset the BMC",
+	Run: func(cmd *cobra.Command, _ []string) {
+		runBMC(cmd.Context(), func(ctx context.Context, queryor device.OutofbandQueryor) error {
+			if err := queryor.ResetBMC(ctx); err != nil {
+				return err
+			}
+
+			fmt.Println("BMC reset initiated")
+
+			return nil
+		})
+	},
+}
+
+var cmdBMCInstallSteps = &cobra.Command{
+	Use:   "install-steps",
+	Short: "List the BMC firmware install steps for a component",
+	Run: func(cmd *cobra.Command, _ []string) {
+		runBMC(cmd.Context(), func(ctx context.Context, queryor device.OutofbandQueryor) error {
+			steps, err := queryor.FirmwareInstallSteps(ctx, component)
+			if err != nil {
+				return err
+			}
+
+			for _, step := range steps {
+				fmt.Println(step)
+			}
+
+			return nil
+		})
+	},
+}
+
+// runBMC logs into the BMC and runs the given func with the queryor,
+// the BMC login is retried as done when installing firmware.
+func runBMC(ctx context.Context, fn func(ctx context.Context, queryor device.OutofbandQueryor) error) {
+	flasher, termCh, err := app.New(
+		model.AppKindCLI,
+		"",
+		cfgFile,
+		logLevel,
+		enableProfiling,
+		model.RunOutofband,
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx, cancelFunc := context.WithCancel(ctx)
+
+	go func() {
+		<-termCh
+		flasher.Logger.Info("got TERM signal, exiting...")
+		cancelFunc()
+	}()
+
+	if err := configureCLIOutofband(flasher); err != nil {
+		flasher.Logger.Fatal(err)
+	}
+
+	if err := resolveBMCCredential(ctx); err != nil {
+		flasher.Logger.Fatal(err)
+	}
+
+	defer redact.Track("", pass)()
+
+	asset := &rtypes.Server{
+		BMCAddress:  addr,
+		BMCUser:     user,
+		BMCPassword: pass,
+	}
+
+	le := flasher.Logger.WithFields(logrus.Fields{"bmc": addr, "mode": model.RunOutofband})
+	queryor := outofband.NewDeviceQueryor(ctx, asset, le)
+
+	if err := queryor.Open(ctx); err != nil {
+		flasher.Logger.Fatal(err)
+	}
+
+	err = fn(ctx, queryor)
+
+	// nolint:errcheck // the session is invalidated on a BMC reset
+	queryor.Close(ctx)
+
+	if err != nil {
+		flasher.Logger.Fatal(err)
+	}
+}
+
+func init() {
+	cmdBMC.PersistentFlags().StringVar(&addr, "addr", "", "BMC host address")
+	cmdBMC.PersistentFlags().StringVar(&user, "user", "", "BMC user")
+	cmdBMC.PersistentFlags().StringVar(&pass, "pass", "", "BMC user password, prefer --credential-ref")
+	cmdBMC.PersistentFlags().StringVar(
+		&credentialRef,
+		"credential-ref",
+		"",
+		"BMC credential reference - file:///path, env://VAR or exec:///path/to/helper [args]",
+	)
+
+	cmdBMC.PersistentFlags().StringVar(&facilityCode, "facility-code", "", "The facility code of the BMC, the outofband tls configuration for the facility is applied")
+
+	if err := cmdBMC.MarkPersistentFlagRequired("addr"); err != nil {
+		log.Fatal(err)
+	}
+
+	cmdBMC.MarkFlagsMutuallyExclusive("pass", "credential-ref")
+
+	cmdBMCInstallSteps.Flags().StringVar(&component, "component", "", "The component slug - bios, bmc, nic")
+
+	if err := cmdBMCInstallSteps.MarkFlagRequired("component"); err != nil {
+		log.Fatal(err)
+	}
+
+	cmdBMC.AddCommand(cmdBMCPower, cmdBMCReset, cmdBMCInstallSteps)
+	rootCmd.AddCommand(cmdBMC)
+}
@@ -0,0 +1,177 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/metal-toolbox/flasher/internal/app"
+	"github.com/metal-toolbox/flasher/internal/inventory"
+	"github.com/metal-toolbox/flasher/internal/model"
+	"github.com/metal-toolbox/flasher/internal/outofband"
+	"github.com/metal-toolbox/flasher/internal/redact"
+	rtypes "github.com/metal-toolbox/rivets/v2/types"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var cmdCapabilities = &cobra.Command{
+	Use:   "capabilities",
+	Short: "List the components the BMC can install firmware on, and the install steps for each",
+	Run: func(cmd *cobra.Command, _ []string) {
+		runCapabilities(cmd.Context())
+	},
+}
+
+func runCapabilities(ctx context.Context) {
+	flasher, termCh, err := app.New(
+		model.AppKindCLI,
+		"",
+		cfgFile,
+		logLevel,
+		enableProfiling,
+		model.RunOutofband,
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx, cancelFunc := context.WithCancel(ctx)
+
+	go func() {
+		<-termCh
+		flasher.Logger.Info("got TERM signal, exiting...")
+		cancelFunc()
+	}()
+
+	if err := configureCLIOutofband(flasher); err != nil {
+		flasher.Logger.Fatal(err)
+	}
+
+	if err := resolveBMCCredential(ctx); err != nil {
+		flasher.Logger.Fatal(err)
+	}
+
+	defer redact.Track("", pass)()
+
+	asset := &rtypes.Server{
+		BMCAddress:  addr,
+		BMCUser:     user,
+		BMCPassword: pass,
+	}
+
+	le := flasher.Logger.WithFields(logrus.Fields{"bmc": addr, "mode": model.RunOutofband})
+
+	capabilities, err := outofband.Capabilities(ctx, outofband.NewDeviceQueryor(ctx, asset, le))
+	if err != nil {
+		flasher.Logger.Fatal(err)
+	}
+
+	if err := writeCapabilities(os.Stdout, inventory.Format(outputFormat), capabilities); err != nil {
+		flasher.Logger.Fatal(err)
+	}
+}
+
+func writeCapabilities(w io.Writer, format inventory.Format, capabilities []*outofband.Capability) error {
+	switch format {
+	case inventory.FormatTable, "":
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "COMPONENT\tVENDOR\tMODEL\tSUPPORTED\tHOST POWER OFF\tBMC RESET\tSTEPS")
+
+		for _, c := range capabilities {
+			steps := make([]string, 0, len(c.Steps))
+			for _, step := range c.Steps {
+				steps = append(steps, string(step))
+			}
+
+			bmcReset := "-"
+			switch {
+			case c.BMCResetPostInstall:
+				bmcReset = "post-install"
+			case c.BMCResetOnInstallFailure:
+				bmcReset = "on-failure"
+			}
+
+			fmt.Fprintf(
+				tw,
+				"%s\t%s\t%s\t%t\t%t\t%s\t%s\n",
+				c.Component,
+				c.Vendor,
+				c.Model,
+				c.Supported,
+				c.HostPowerOffPreInstall,
+				bmcReset,
+				strings.Join(steps, ","),
+			)
+		}
+
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+
+		// list the reason each unsupported component is not supported
+		header := "\nunsupported components:"
+		for _, c := range capabilities {
+			if c.Supported {
+				continue
+			}
+
+			if header != "" {
+				fmt.Fprintln(w, header)
+				header = ""
+			}
+
+			fmt.Fprintf(w, "  %s: %s\n", c.Component, c.Reason)
+		}
+
+		return nil
+
+	case inventory.FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+
+		return enc.Encode(capabilities)
+
+	case inventory.FormatYAML:
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+
+		if err := enc.Encode(capabilities); err != nil {
+			return err
+		}
+
+		return enc.Close()
+
+	default:
+		return errors.Wrap(inventory.ErrFormat, string(format))
+	}
+}
+
+func init() {
+	cmdCapabilities.Flags().StringVar(&addr, "addr", "", "BMC host address")
+	cmdCapabilities.Flags().StringVar(&user, "user", "", "BMC user")
+	cmdCapabilities.Flags().StringVar(&pass, "pass", "", "BMC user password, prefer --credential-ref")
+	cmdCapabilities.Flags().StringVar(
+		&credentialRef,
+		"credential-ref",
+		"",
+		"BMC credential reference - file:///path, env://VAR or exec:///path/to/helper [args]",
+	)
+	cmdCapabilities.Flags().StringVar(&facilityCode, "facility-code", "", "The facility code of the BMC, the outofband tls configuration for the facility is applied")
+	cmdCapabilities.Flags().StringVarP(&outputFormat, "output", "o", string(inventory.FormatTable), "output format - table, json, yaml")
+
+	if err := cmdCapabilities.MarkFlagRequired("addr"); err != nil {
+		log.Fatal(err)
+	}
+
+	cmdCapabilities.MarkFlagsMutuallyExclusive("pass", "credential-ref")
+
+	rootCmd.AddCommand(cmdCapabilities)
+}
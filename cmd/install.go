@@ -3,32 +3,50 @@ package cmd
 import (
 	"context"
 	"log"
+	"os"
 
 	"github.com/metal-toolbox/flasher/internal/app"
 	"github.com/metal-toolbox/flasher/internal/install"
 	"github.com/metal-toolbox/flasher/internal/model"
+	"github.com/metal-toolbox/flasher/internal/secrets"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
 
 var cmdInstall = &cobra.Command{
 	Use:   "install",
 	Short: "Install given firmware for a component",
+	// the flags are validated before any install, so an invalid report format does not fail after the installs
+	PreRunE: func(_ *cobra.Command, _ []string) error {
+		return install.ReportFormat(reportFormat).Validate()
+	},
 	Run: func(cmd *cobra.Command, _ []string) {
 		runInstall(cmd.Context())
 	},
 }
 
 var (
-	fwvendor  string
-	fwmodel   string
-	fwversion string
-	component string
-	file      string
-	addr      string
-	user      string
-	pass      string
-	force     bool
-	onlyPlan  bool
+	fwvendor      string
+	fwmodel       string
+	fwversion     string
+	component     string
+	file          string
+	fwURL         string
+	checksum      string
+	addr          string
+	user          string
+	pass          string
+	credentialRef string
+	manifest      string
+	targets       string
+	parallel      int
+	report        string
+	reportFormat  string
+	resultFile    string
+	statusFile    string
+	force         bool
+	onlyPlan      bool
 )
 
 func runInstall(ctx context.Context) {
@@ -54,13 +72,37 @@ func runInstall(ctx context.Context) {
 		cancelFunc()
 	}()
 
+	if err := configureCLIOutofband(flasher); err != nil {
+		flasher.Logger.Fatal(err)
+	}
+
+	// in fleet mode the target BMC credentials may be set in the targets file
+	if targets == "" || credentialRef != "" {
+		if err := resolveBMCCredential(ctx); err != nil {
+			flasher.Logger.Fatal(err)
+		}
+	}
+
+	var firmwares []*install.Firmware
+	if manifest != "" {
+		m, err := install.LoadManifest(manifest)
+		if err != nil {
+			flasher.Logger.Fatal(err)
+		}
+
+		firmwares = m.Firmwares
+	}
+
 	p := &install.Params{
 		DryRun:    dryrun,
 		Version:   fwversion,
 		File:      file,
+		URL:       fwURL,
+		Checksum:  checksum,
 		Component: component,
 		Model:     fwmodel,
 		Vendor:    fwvendor,
+		Firmwares: firmwares,
 		User:      user,
 		Pass:      pass,
 		BmcAddr:   addr,
@@ -70,7 +112,100 @@ func runInstall(ctx context.Context) {
 
 	installer := install.New(flasher.Logger)
 
-	installer.Install(ctx, p)
+	if targets != "" {
+		installFleet(ctx, flasher.Logger, installer, p)
+		return
+	}
+
+	// render the install progress on stderr, stdout is written with the result
+	publisher := install.NewTerminalPublisher(os.Stderr, install.IsTerminal(os.Stderr))
+	p.Publisher = publisher
+	p.StatusFile = statusFile
+
+	// the error is included in the result, the outcome is mapped to the exit code
+	result, _ := installer.Install(ctx, p)
+
+	publisher.Done()
+
+	if err := writeInstallResult(result); err != nil {
+		flasher.Logger.Fatal(err)
+	}
+
+	os.Exit(result.Outcome.ExitCode())
+}
+
+func writeInstallResult(result *install.Result) error {
+	if resultFile == "" {
+		return install.WriteResult(os.Stdout, result)
+	}
+
+	fh, err := os.Create(resultFile)
+	if err != nil {
+		return err
+	}
+
+	defer fh.Close()
+
+	return install.WriteResult(fh, result)
+}
+
+// installFleet installs the firmware on each of the targets and writes out the report,
+// exiting with a non-zero status if the install failed on any target.
+func installFleet(ctx context.Context, logger *logrus.Logger, installer *install.Installer, p *install.Params) {
+	list, err := install.LoadTargets(targets)
+	if err != nil {
+		logger.Fatal(err)
+	}
+
+	results := installer.InstallFleet(ctx, p, list, parallel, os.Stderr)
+
+	if err := writeFleetReport(results); err != nil {
+		logger.Fatal(err)
+	}
+
+	for _, result := range results {
+		if result.State == install.TargetFailed {
+			os.Exit(1)
+		}
+	}
+}
+
+func writeFleetReport(results []*install.TargetResult) error {
+	if report == "" {
+		return install.WriteReport(os.Stdout, install.ReportFormat(reportFormat), results)
+	}
+
+	fh, err := os.Create(report)
+	if err != nil {
+		return err
+	}
+
+	defer fh.Close()
+
+	return install.WriteReport(fh, install.ReportFormat(reportFormat), results)
+}
+
+// resolveBMCCredential sets the BMC user, password from the credential reference when given,
+// the username is set if included in the credential.
+func resolveBMCCredential(ctx context.Context) error {
+	if credentialRef != "" {
+		cred, err := secrets.ResolveCredential(ctx, credentialRef)
+		if err != nil {
+			return err
+		}
+
+		if user == "" {
+			user = cred.Username
+		}
+
+		pass = cred.Password
+	}
+
+	if user == "" || pass == "" {
+		return errors.New("BMC user and password required, set --user with --credential-ref or --pass")
+	}
+
+	return nil
 }
 
 func init() {
@@ -79,19 +214,48 @@ func init() {
 	cmdInstall.Flags().BoolVarP(&force, "force", "", false, "force install, skip checking existing version")
 	cmdInstall.Flags().StringVar(&fwversion, "version", "", "The version of the firmware being installed")
 	cmdInstall.Flags().StringVar(&file, "file", "", "The firmware file")
+	cmdInstall.Flags().StringVar(&fwURL, "url", "", "The firmware download URL, in place of --file")
+	cmdInstall.Flags().StringVar(&checksum, "checksum", "", "The firmware file checksum, required with --url - md5sum:<checksum>")
 	cmdInstall.Flags().StringVar(&addr, "addr", "", "BMC host address")
 	cmdInstall.Flags().StringVar(&user, "user", "", "BMC user")
 	cmdInstall.Flags().StringVar(&fwvendor, "vendor", "", "Component vendor")
 	cmdInstall.Flags().StringVar(&fwmodel, "model", "", "Component model")
-	cmdInstall.Flags().StringVar(&pass, "pass", "", "BMC user password")
+	cmdInstall.Flags().StringVar(&pass, "pass", "", "BMC user password, prefer --credential-ref")
+	cmdInstall.Flags().StringVar(
+		&credentialRef,
+		"credential-ref",
+		"",
+		"BMC credential reference - file:///path, env://VAR or exec:///path/to/helper [args]",
+	)
 	cmdInstall.Flags().StringVar(&component, "component", "", "The component slug the firmware applies to")
+	cmdInstall.Flags().StringVar(&resultFile, "result", "", "The file to write the install result to, defaults to stdout")
+	cmdInstall.Flags().StringVar(&statusFile, "status-file", "", "The file to write the task status record to once the install completes")
+	cmdInstall.Flags().StringVar(&manifest, "manifest", "", "A YAML or JSON manifest listing the firmware to install in a single task")
+	cmdInstall.Flags().StringVar(&targets, "targets", "", "A CSV file listing the BMCs to install firmware on - columns addr, user, pass, credential_ref")
+	cmdInstall.Flags().IntVar(&parallel, "parallel", 1, "The number of targets to install firmware on concurrently")
+	cmdInstall.Flags().StringVar(&report, "report", "", "The file to write the targets install report to, defaults to stdout")
+	cmdInstall.Flags().StringVar(&reportFormat, "report-format", string(install.ReportJSON), "The targets install report format - json, csv")
+	cmdInstall.Flags().StringVar(&facilityCode, "facility-code", "", "The facility code of the BMC, the outofband tls configuration for the facility is applied")
 
-	required := []string{"version", "file", "component", "addr", "user", "pass", "vendor", "model"}
-	for _, r := range required {
-		if err := cmdInstall.MarkFlagRequired(r); err != nil {
-			log.Fatal(err)
-		}
+	cmdInstall.MarkFlagsMutuallyExclusive("pass", "credential-ref")
+
+	// either a single firmware is installed, or the firmware listed in the manifest
+	single := []string{"version", "component", "vendor", "model"}
+	cmdInstall.MarkFlagsRequiredTogether(single...)
+	cmdInstall.MarkFlagsOneRequired("manifest", "component")
+
+	for _, flag := range append(single, "file", "url", "checksum") {
+		cmdInstall.MarkFlagsMutuallyExclusive("manifest", flag)
 	}
 
+	// the firmware is read from the file, or downloaded from the url and its checksum validated
+	cmdInstall.MarkFlagsMutuallyExclusive("file", "url")
+	cmdInstall.MarkFlagsRequiredTogether("url", "checksum")
+
+	cmdInstall.MarkFlagsOneRequired("addr", "targets")
+	cmdInstall.MarkFlagsMutuallyExclusive("addr", "targets")
+	cmdInstall.MarkFlagsMutuallyExclusive("result", "targets")
+	cmdInstall.MarkFlagsMutuallyExclusive("status-file", "targets")
+
 	rootCmd.AddCommand(cmdInstall)
 }
@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"context"
+	"log"
+	"os"
+
+	"github.com/metal-toolbox/flasher/internal/app"
+	"github.com/metal-toolbox/flasher/internal/inband"
+	"github.com/metal-toolbox/flasher/internal/inventory"
+	"github.com/metal-toolbox/flasher/internal/model"
+	"github.com/metal-toolbox/flasher/internal/outofband"
+	"github.com/metal-toolbox/flasher/internal/redact"
+	rtypes "github.com/metal-toolbox/rivets/v2/types"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var cmdInventory = &cobra.Command{
+	Use:   "inventory",
+	Short: "Collect and list device components as identified by flasher",
+	Run: func(cmd *cobra.Command, _ []string) {
+		runInventory(cmd.Context())
+	},
+}
+
+var (
+	inventoryInband bool
+	outputFormat    string
+)
+
+func runInventory(ctx context.Context) {
+	mode := model.RunOutofband
+	if inventoryInband {
+		mode = model.RunInband
+	}
+
+	flasher, termCh, err := app.New(
+		model.AppKindCLI,
+		"",
+		cfgFile,
+		logLevel,
+		enableProfiling,
+		mode,
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx, cancelFunc := context.WithCancel(ctx)
+
+	go func() {
+		<-termCh
+		flasher.Logger.Info("got TERM signal, exiting...")
+		cancelFunc()
+	}()
+
+	var components []*inventory.Component
+
+	if inventoryInband {
+		le := flasher.Logger.WithField("mode", model.RunInband)
+
+		components, err = inventory.Inband(ctx, inband.NewDeviceQueryor(le))
+		if err != nil {
+			flasher.Logger.Fatal(err)
+		}
+	} else {
+		if addr == "" {
+			flasher.Logger.Fatal("--addr parameter required for out-of-band inventory")
+		}
+
+		if err := configureCLIOutofband(flasher); err != nil {
+			flasher.Logger.Fatal(err)
+		}
+
+		if err := resolveBMCCredential(ctx); err != nil {
+			flasher.Logger.Fatal(err)
+		}
+
+		defer redact.Track("", pass)()
+
+		asset := &rtypes.Server{
+			BMCAddress:  addr,
+			BMCUser:     user,
+			BMCPassword: pass,
+		}
+
+		le := flasher.Logger.WithFields(logrus.Fields{"bmc": addr, "mode": model.RunOutofband})
+
+		components, err = inventory.Outofband(ctx, outofband.NewDeviceQueryor(ctx, asset, le))
+		if err != nil {
+			flasher.Logger.Fatal(err)
+		}
+	}
+
+	if err := inventory.Write(os.Stdout, inventory.Format(outputFormat), components); err != nil {
+		flasher.Logger.Fatal(err)
+	}
+}
+
+func init() {
+	cmdInventory.Flags().BoolVarP(&inventoryInband, "inband", "", false, "collect inventory from the host this command runs on")
+	cmdInventory.Flags().StringVar(&addr, "addr", "", "BMC host address")
+	cmdInventory.Flags().StringVar(&user, "user", "", "BMC user")
+	cmdInventory.Flags().StringVar(&pass, "pass", "", "BMC user password, prefer --credential-ref")
+	cmdInventory.Flags().StringVar(
+		&credentialRef,
+		"credential-ref",
+		"",
+		"BMC credential reference - file:///path, env://VAR or exec:///path/to/helper [args]",
+	)
+	cmdInventory.Flags().StringVar(&facilityCode, "facility-code", "", "The facility code of the BMC, the outofband tls configuration for the facility is applied")
+	cmdInventory.Flags().StringVarP(&outputFormat, "output", "o", string(inventory.FormatTable), "output format - table, json, yaml")
+
+	cmdInventory.MarkFlagsMutuallyExclusive("pass", "credential-ref")
+	cmdInventory.MarkFlagsMutuallyExclusive("inband", "addr")
+
+	rootCmd.AddCommand(cmdInventory)
+}
@@ -0,0 +1,193 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"text/tabwriter"
+
+	"github.com/google/uuid"
+	"github.com/metal-toolbox/flasher/internal/app"
+	"github.com/metal-toolbox/flasher/internal/inventory"
+	"github.com/metal-toolbox/flasher/internal/model"
+	"github.com/metal-toolbox/flasher/internal/redact"
+	"github.com/metal-toolbox/flasher/internal/worker"
+	rctypes "github.com/metal-toolbox/rivets/v2/condition"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var cmdPlan = &cobra.Command{
+	Use:   "plan",
+	Short: "List the firmware installs the worker would perform for an asset, without installing firmware",
+	Run: func(cmd *cobra.Command, _ []string) {
+		runPlan(cmd.Context())
+	},
+}
+
+var (
+	planAssetID     string
+	planFirmwareSet string
+	planInband      bool
+)
+
+func runPlan(ctx context.Context) {
+	mode := model.RunOutofband
+	if planInband {
+		mode = model.RunInband
+	}
+
+	flasher, termCh, err := app.New(
+		model.AppKindCLI,
+		model.StoreKind(storeKind),
+		cfgFile,
+		logLevel,
+		enableProfiling,
+		mode,
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx, cancelFunc := context.WithCancel(ctx)
+
+	go func() {
+		<-termCh
+		flasher.Logger.Info("got TERM signal, exiting...")
+		cancelFunc()
+	}()
+
+	assetID, err := uuid.Parse(planAssetID)
+	if err != nil {
+		flasher.Logger.Fatal(errors.Wrap(err, "--asset-id parameter invalid"))
+	}
+
+	repository, err := initStore(ctx, flasher.Config, flasher.Logger)
+	if err != nil {
+		flasher.Logger.Fatal(err)
+	}
+
+	asset, err := repository.AssetByID(ctx, assetID.String())
+	if err != nil {
+		flasher.Logger.Fatal(err)
+	}
+
+	defer redact.Track(asset.BMCAddress, asset.BMCPassword)()
+
+	if err := configureOutofband(flasher.Config.OutofbandOptions, asset.Facility, flasher.Logger); err != nil {
+		flasher.Logger.Fatal(err)
+	}
+
+	params := &rctypes.FirmwareInstallTaskParameters{
+		AssetID:      assetID,
+		ForceInstall: force,
+	}
+
+	if planFirmwareSet != "" {
+		params.FirmwareSetID, err = uuid.Parse(planFirmwareSet)
+		if err != nil {
+			flasher.Logger.Fatal(errors.Wrap(err, "--firmware-set parameter invalid"))
+		}
+	} else {
+		// without a firmware set, plan with the firmware applicable to the device vendor, model
+		firmwares, err := repository.FirmwareByDeviceVendorModel(ctx, asset.Vendor, asset.Model)
+		if err != nil {
+			flasher.Logger.Fatal(err)
+		}
+
+		for _, fw := range firmwares {
+			params.Firmwares = append(params.Firmwares, *fw)
+		}
+	}
+
+	task, err := model.NewTask(uuid.New(), rctypes.FirmwareInstall, params)
+	if err != nil {
+		flasher.Logger.Fatal(err)
+	}
+
+	task.Server = asset
+
+	le := flasher.Logger.WithFields(logrus.Fields{"assetID": asset.ID, "mode": mode})
+
+	plan, err := worker.PlanTask(ctx, mode, &task, repository, nil, le)
+	if err != nil {
+		flasher.Logger.Fatal(err)
+	}
+
+	if err := writePlan(os.Stdout, inventory.Format(outputFormat), plan); err != nil {
+		flasher.Logger.Fatal(err)
+	}
+}
+
+// writePlan writes the firmware install decisions and the planned actions in the given format.
+func writePlan(w io.Writer, format inventory.Format, plan *worker.Plan) error {
+	switch format {
+	case inventory.FormatTable, "":
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "COMPONENT\tVERSION\tCURRENT\tDECISION\tREASON")
+
+		for _, d := range plan.Decisions {
+			decision := "skipped"
+			if d.Queued {
+				decision = "queued"
+			}
+
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", d.Component, d.Version, d.CurrentVersion, decision, d.Reason)
+		}
+
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+
+		for _, a := range plan.Actions {
+			fmt.Fprintf(w, "\n%s %s (%s)\n", a.Component, a.Version, a.InstallMethod)
+
+			for idx, step := range a.Steps {
+				fmt.Fprintf(w, "  %d. %s\n", idx+1, step)
+			}
+		}
+
+		return nil
+
+	case inventory.FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+
+		return enc.Encode(plan)
+
+	case inventory.FormatYAML:
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+
+		if err := enc.Encode(plan); err != nil {
+			return err
+		}
+
+		return enc.Close()
+
+	default:
+		return errors.Wrap(inventory.ErrFormat, string(format))
+	}
+}
+
+func init() {
+	cmdPlan.Flags().StringVar(&planAssetID, "asset-id", "", "The asset identifier to plan firmware installs for")
+	cmdPlan.Flags().StringVar(&planFirmwareSet, "firmware-set", "", "The firmware set identifier, defaults to the firmware applicable to the asset vendor, model")
+	cmdPlan.Flags().StringVar(&storeKind, "store", "", "Inventory store to lookup the asset, firmware - serverservice")
+	cmdPlan.Flags().BoolVarP(&planInband, "inband", "", false, "plan inband firmware installs on the host this command runs on")
+	cmdPlan.Flags().BoolVarP(&force, "force", "", false, "plan installs regardless of the currently installed firmware version")
+	cmdPlan.Flags().StringVarP(&outputFormat, "output", "o", string(inventory.FormatTable), "output format - table, json, yaml")
+
+	for _, flag := range []string{"asset-id", "store"} {
+		if err := cmdPlan.MarkFlagRequired(flag); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	rootCmd.AddCommand(cmdPlan)
+}
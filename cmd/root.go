@@ -46,5 +46,5 @@ func Execute() {
 func init() {
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.flasher.yml)")
 	rootCmd.PersistentFlags().BoolVarP(&enableProfiling, "enable-pprof", "", false, "Enable profiling endpoint at: "+"http://localhost:9091")
-	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", "info", "set logging level - debug, trace")
+	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", "", "set logging level - trace, debug, info, warn, error, overrides the configured log level (default info)")
 }
@@ -2,26 +2,34 @@ package cmd
 
 import (
 	"context"
-	"fmt"
 	"log"
+	"net/http"
+	"net/url"
 	"os"
+	"strings"
+	"time"
 
-	"github.com/go-logr/zapr"
+	"github.com/coreos/go-oidc/v3/oidc"
 	"github.com/google/uuid"
+	orc "github.com/metal-toolbox/conditionorc/pkg/api/v1/orchestrator/client"
 	"github.com/metal-toolbox/ctrl"
 	"github.com/metal-toolbox/flasher/internal/app"
+	"github.com/metal-toolbox/flasher/internal/logging"
 	"github.com/metal-toolbox/flasher/internal/metrics"
 	"github.com/metal-toolbox/flasher/internal/model"
 	"github.com/metal-toolbox/flasher/internal/otel"
+	"github.com/metal-toolbox/flasher/internal/outofband"
+	"github.com/metal-toolbox/flasher/internal/secrets"
 	"github.com/metal-toolbox/flasher/internal/store"
 	"github.com/metal-toolbox/flasher/internal/worker"
-	"go.uber.org/zap"
-	"go.uber.org/zap/zapcore"
 
 	rctypes "github.com/metal-toolbox/rivets/v2/condition"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
 
 	// nolint:gosec // profiling endpoint listens on localhost.
 	_ "net/http/pprof"
@@ -57,6 +65,9 @@ var (
 	ErrInventoryStore = errors.New("inventory store error")
 )
 
+// orcQueryTimeout is the orchestrator API request timeout, this matches the timeout of the controller client.
+const orcQueryTimeout = 60 * time.Second
+
 func runWorker(ctx context.Context, mode model.RunMode) {
 	flasher, termCh, err := app.New(
 		model.AppKindWorker,
@@ -73,25 +84,11 @@ func runWorker(ctx context.Context, mode model.RunMode) {
 	// serve metrics endpoint
 	metrics.ListenAndServe()
 
-	config := zap.NewProductionConfig()
-	config.OutputPaths = []string{"stdout"}
-	switch logLevel {
-	case "debug":
-		config.Level = zap.NewAtomicLevelAt(zapcore.DebugLevel)
-	default:
-		config.Level = zap.NewAtomicLevelAt(zapcore.InfoLevel)
-	}
-	zapLogger, err := config.Build()
-	if err != nil {
-		panic(fmt.Sprintf("who watches the watchmen (%v)?", err))
-	}
-	lg := zapr.NewLogger(zapLogger)
-
 	oCfg := otel.Config{
 		Servicename: "flasher-" + string(mode),
 		Endpoint:    os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"),
 		Insecure:    false,
-		Logger:      lg,
+		Logger:      logging.Logr(logging.PkgOtel),
 	}
 	ctx, otelShutdown, err := otel.Init(ctx, oCfg)
 	if err != nil {
@@ -136,6 +133,10 @@ func runOutofband(ctx context.Context, flasher *app.App, repository store.Reposi
 		flasher.Logger.Fatal(err)
 	}
 
+	if err := configureOutofband(flasher.Config.OutofbandOptions, facilityCode, flasher.Logger); err != nil {
+		flasher.Logger.Fatal(err)
+	}
+
 	nc := ctrl.NewNatsController(
 		model.AppName,
 		facilityCode,
@@ -145,7 +146,7 @@ func runOutofband(ctx context.Context, flasher *app.App, repository store.Reposi
 		rctypes.FirmwareInstall,
 		ctrl.WithConcurrency(flasher.Config.Concurrency),
 		ctrl.WithKVReplicas(natsCfg.KVReplicas),
-		ctrl.WithLogger(flasher.Logger),
+		ctrl.WithLogger(logging.Logger(logging.PkgCtrl)),
 		ctrl.WithConnectionTimeout(natsCfg.ConnectTimeout),
 	)
 
@@ -163,8 +164,76 @@ func runOutofband(ctx context.Context, flasher *app.App, repository store.Reposi
 	)
 }
 
+// configureOutofband applies the out-of-band install configuration parameters for the facility.
+func configureOutofband(cfg *app.OutofbandOptions, facility string, logger *logrus.Logger) error {
+	if cfg == nil {
+		cfg = &app.OutofbandOptions{}
+	}
+
+	if cfg.PollBackoff != nil {
+		if err := outofband.SetPollBackoff(outofband.PollBackoff{
+			Min:           cfg.PollBackoff.Min,
+			Max:           cfg.PollBackoff.Max,
+			Factor:        cfg.PollBackoff.Factor,
+			Timeout:       cfg.PollBackoff.Timeout,
+			DisableJitter: cfg.PollBackoff.DisableJitter,
+		}); err != nil {
+			return err
+		}
+	}
+
+	if cfg.BMCLimits != nil {
+		if err := outofband.SetBMCRegistryParams(outofband.BMCRegistryParams{
+			RequestsPerSecond: cfg.BMCLimits.RequestsPerSecond,
+			Burst:             cfg.BMCLimits.Burst,
+			FailureThreshold:  cfg.BMCLimits.FailureThreshold,
+			Cooldown:          cfg.BMCLimits.Cooldown,
+		}); err != nil {
+			return err
+		}
+	}
+
+	// BMC certificates are verified when the tls parameters are configured for the facility,
+	// without these the certificates are not verified as in previous releases, with a warning logged.
+	//
+	// viper lower cases map keys
+	tlsCfg, exists := cfg.TLS[strings.ToLower(facility)]
+	if !exists || tlsCfg == nil {
+		logger.WithField("facility", facility).Warn(
+			"outofband tls configuration not found for facility, BMC certificates will NOT be verified - " +
+				"configure a ca_bundle and/or pin_store, or set insecure_skip_verify to disable BMC certificate verification",
+		)
+
+		return nil
+	}
+
+	if err := outofband.SetBMCTLSParams(outofband.TLSParams{
+		InsecureSkipVerify: tlsCfg.InsecureSkipVerify,
+		CABundle:           tlsCfg.CABundle,
+		PinStore:           tlsCfg.PinStore,
+		TrustOnFirstUse:    tlsCfg.TrustOnFirstUse,
+	}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func runInband(ctx context.Context, flasher *app.App, repository store.Repository) {
 	cfgOrcAPI := flasher.Config.OrchestratorAPIParams
+
+	options := []ctrl.OptionHTTPController{ctrl.WithNATSHTTPLogger(logging.Logger(logging.PkgCtrl))}
+
+	// the client secret is resolved from the secret reference on each token request
+	if cfgOrcAPI.OidcClientSecretRef != "" && !cfgOrcAPI.AuthDisabled {
+		orcClient, err := newOrchestratorClient(ctx, cfgOrcAPI)
+		if err != nil {
+			flasher.Logger.Fatal(errors.Wrap(err, "orchestrator api client"))
+		}
+
+		options = append(options, ctrl.WithOrchestratorClient(orcClient))
+	}
+
 	orcConfig := &ctrl.OrchestratorAPIConfig{
 		Endpoint:             cfgOrcAPI.Endpoint,
 		AuthDisabled:         cfgOrcAPI.AuthDisabled,
@@ -181,7 +250,7 @@ func runInband(ctx context.Context, flasher *app.App, repository store.Repositor
 		uuid.MustParse(flasher.Config.ServerID),
 		rctypes.FirmwareInstallInband,
 		orcConfig,
-		ctrl.WithNATSHTTPLogger(flasher.Logger),
+		options...,
 	)
 	if err != nil {
 		flasher.Logger.Fatal(err)
@@ -224,3 +293,41 @@ func init() {
 
 	rootCmd.AddCommand(cmdRun)
 }
+
+// configureCLIOutofband loads the configuration file for CLI commands that connect to a BMC directly,
+// and applies the out-of-band configuration parameters for the --facility-code.
+func configureCLIOutofband(flasher *app.App) error {
+	if err := flasher.LoadConfiguration(cfgFile, ""); err != nil {
+		return err
+	}
+
+	return configureOutofband(flasher.Config.OutofbandOptions, facilityCode, flasher.Logger)
+}
+
+// newOrchestratorClient returns an orchestrator API client which authenticates with a client secret
+// resolved from the secret reference on each token request, so a rotated secret is picked up when the token expires.
+func newOrchestratorClient(ctx context.Context, cfg *app.OrchestratorAPIParams) (orc.Queryor, error) {
+	// setup oidc provider
+	provider, err := oidc.NewProvider(ctx, cfg.OidcIssuerEndpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	// setup oauth configuration
+	oauthConfig := clientcredentials.Config{
+		ClientID:       cfg.OidcClientID,
+		TokenURL:       provider.Endpoint().TokenURL,
+		Scopes:         cfg.OidcClientScopes,
+		EndpointParams: url.Values{"audience": []string{cfg.OidcAudienceEndpoint}},
+	}
+
+	oAuthclient := oauth2.NewClient(ctx, secrets.ClientCredentialsTokenSource(ctx, &oauthConfig, cfg.OidcClientSecretRef))
+
+	client := &http.Client{
+		Timeout:   orcQueryTimeout,
+		Transport: otelhttp.NewTransport(oAuthclient.Transport),
+		Jar:       oAuthclient.Jar,
+	}
+
+	return orc.NewClient(cfg.Endpoint, orc.WithHTTPClient(client))
+}
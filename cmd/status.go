@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/google/uuid"
+	"github.com/metal-toolbox/flasher/internal/app"
+	"github.com/metal-toolbox/flasher/internal/install"
+	"github.com/metal-toolbox/flasher/internal/model"
+	"github.com/metal-toolbox/flasher/internal/status"
+	"github.com/nats-io/nats.go"
+	"github.com/spf13/cobra"
+)
+
+var cmdStatus = &cobra.Command{
+	Use:   "status <condition-id>",
+	Short: "Show the firmware install condition status as published by the worker",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		runStatus(cmd.Context(), args[0])
+	},
+}
+
+var (
+	statusWatch bool
+	statusLog   bool
+)
+
+func runStatus(ctx context.Context, conditionID string) {
+	flasher, termCh, err := app.New(
+		model.AppKindCLI,
+		"",
+		cfgFile,
+		logLevel,
+		enableProfiling,
+		model.RunOutofband,
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if _, err := uuid.Parse(conditionID); err != nil {
+		flasher.Logger.Fatal("invalid condition id: " + err.Error())
+	}
+
+	// the NATS parameters are read from the worker configuration
+	if err := flasher.LoadConfiguration(cfgFile, ""); err != nil {
+		flasher.Logger.Fatal(err)
+	}
+
+	natsCfg, err := flasher.NatsParams()
+	if err != nil {
+		flasher.Logger.Fatal(err)
+	}
+
+	ctx, cancelFunc := context.WithCancel(ctx)
+
+	go func() {
+		<-termCh
+		cancelFunc()
+	}()
+
+	nc, err := nats.Connect(
+		natsCfg.NatsURL,
+		nats.UserCredentials(natsCfg.CredsFile),
+		nats.Timeout(natsCfg.ConnectTimeout),
+		nats.Name(model.AppName+"-status"),
+	)
+	if err != nil {
+		flasher.Logger.Fatal(err)
+	}
+
+	defer nc.Close()
+
+	js, err := nc.JetStream()
+	if err != nil {
+		flasher.Logger.Fatal(err)
+	}
+
+	queryor, err := status.NewQueryor(js, facilityCode)
+	if err != nil {
+		flasher.Logger.Fatal(err)
+	}
+
+	if !statusWatch {
+		condition, err := queryor.Get(conditionID)
+		if err != nil {
+			flasher.Logger.Fatal(err)
+		}
+
+		if err := status.Write(os.Stdout, condition); err != nil {
+			flasher.Logger.Fatal(err)
+		}
+
+		if statusLog {
+			if err := status.WriteLog(os.Stdout, condition); err != nil {
+				flasher.Logger.Fatal(err)
+			}
+		}
+
+		return
+	}
+
+	tty := install.IsTerminal(os.Stdout)
+
+	err = queryor.Watch(ctx, conditionID, func(condition *status.Condition) error {
+		// redraw on a terminal, otherwise each update is written in sequence
+		if tty {
+			fmt.Print("\033[H\033[2J")
+		} else {
+			fmt.Println("---")
+		}
+
+		if err := status.Write(os.Stdout, condition); err != nil {
+			return err
+		}
+
+		if statusLog {
+			return status.WriteLog(os.Stdout, condition)
+		}
+
+		return nil
+	})
+
+	if err != nil && ctx.Err() == nil {
+		flasher.Logger.Fatal(err)
+	}
+}
+
+func init() {
+	cmdStatus.Flags().StringVar(&facilityCode, "facility-code", "", "The facility code of the worker the condition was published by")
+	cmdStatus.Flags().BoolVarP(&statusWatch, "watch", "w", false, "Watch and render the status on each update, until the condition is complete")
+	cmdStatus.Flags().BoolVar(&statusLog, "log", false, "Include the task log published by the worker with the final task state")
+
+	if err := cmdStatus.MarkFlagRequired("facility-code"); err != nil {
+		log.Fatal(err)
+	}
+
+	rootCmd.AddCommand(cmdStatus)
+}
@@ -8,8 +8,10 @@ import (
 	"syscall"
 	"time"
 
-	runtime "github.com/banzaicloud/logrus-runtime-formatter"
+	"github.com/metal-toolbox/flasher/internal/logging"
 	"github.com/metal-toolbox/flasher/internal/model"
+	"github.com/metal-toolbox/flasher/internal/redact"
+	"github.com/metal-toolbox/flasher/internal/tasklog"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
@@ -55,24 +57,16 @@ func New(appKind model.AppKind, storeKind model.StoreKind, cfgFile, loglevel str
 		Mode:   mode,
 	}
 
-	switch model.LogLevel(loglevel) {
-	case model.LogLevelDebug:
-		app.Logger.Level = logrus.DebugLevel
-	case model.LogLevelTrace:
-		app.Logger.Level = logrus.TraceLevel
-	default:
-		app.Logger.Level = logrus.InfoLevel
+	// CLI commands log in the text format, the worker logs in the JSON format unless configured otherwise
+	logFormat := logging.FormatJSON
+	if appKind == model.AppKindCLI {
+		logFormat = logging.FormatText
 	}
 
-	runtimeFormatter := &runtime.Formatter{
-		ChildFormatter: &logrus.JSONFormatter{},
-		File:           true,
-		Line:           true,
-		BaseNameOnly:   true,
+	if err := logging.Configure(app.Logger, logging.Params{Level: loglevel, Format: logFormat}); err != nil {
+		return nil, nil, errors.Wrap(ErrAppInit, err.Error())
 	}
 
-	app.Logger.SetFormatter(runtimeFormatter)
-
 	// register for SIGINT, SIGTERM
 	termCh := make(chan os.Signal, 1)
 	signal.Notify(termCh, syscall.SIGINT, syscall.SIGTERM)
@@ -81,8 +75,8 @@ func New(appKind model.AppKind, storeKind model.StoreKind, cfgFile, loglevel str
 		enableProfilingEndpoint()
 	}
 
-	if appKind == model.AppKindCLI {
-		runtimeFormatter.ChildFormatter = &logrus.TextFormatter{}
+	// CLI commands load configuration only when they lookup the inventory store
+	if appKind == model.AppKindCLI && storeKind == "" {
 		return app, termCh, nil
 	}
 
@@ -90,9 +84,62 @@ func New(appKind model.AppKind, storeKind model.StoreKind, cfgFile, loglevel str
 		return nil, nil, err
 	}
 
+	if err := app.configureLogging(loglevel, logFormat); err != nil {
+		return nil, nil, errors.Wrap(ErrConfig, err.Error())
+	}
+
+	if app.Config.Redaction != nil {
+		if err := redact.Configure(app.Config.Redaction.Patterns, !app.Config.Redaction.KeepHosts); err != nil {
+			return nil, nil, errors.Wrap(ErrConfig, err.Error())
+		}
+	}
+
+	if app.Config.StatusHistory != nil {
+		if err := model.SetStatusHistoryParams(model.StatusHistoryParams{
+			MaxBytes: app.Config.StatusHistory.MaxBytes,
+			Head:     app.Config.StatusHistory.Head,
+			SpillDir: app.Config.StatusHistory.SpillDir,
+		}); err != nil {
+			return nil, nil, errors.Wrap(ErrConfig, err.Error())
+		}
+	}
+
+	if app.Config.TaskLog != nil {
+		if err := tasklog.SetParams(tasklog.Params{
+			MaxBytes:       app.Config.TaskLog.MaxBytes,
+			Dir:            app.Config.TaskLog.Dir,
+			DisablePublish: app.Config.TaskLog.DisablePublish,
+		}); err != nil {
+			return nil, nil, errors.Wrap(ErrConfig, err.Error())
+		}
+	}
+
+	if err := model.SetDefaultTaskTimeout(app.Config.TaskTimeout); err != nil {
+		return nil, nil, errors.Wrap(ErrConfig, err.Error())
+	}
+
 	return app, termCh, nil
 }
 
+// configureLogging applies the logging configuration, the log level set by the CLI flag
+// takes precedence over the configured log level.
+func (a *App) configureLogging(flagLevel, defaultFormat string) error {
+	params := logging.Params{Level: a.Config.LogLevel, Format: defaultFormat}
+	if flagLevel != "" {
+		params.Level = flagLevel
+	}
+
+	if a.Config.Logging != nil {
+		if a.Config.Logging.Format != "" {
+			params.Format = a.Config.Logging.Format
+		}
+
+		params.Packages = a.Config.Logging.Packages
+	}
+
+	return logging.Configure(a.Logger, params)
+}
+
 // enableProfilingEndpoint enables the profiling endpoint
 func enableProfilingEndpoint() {
 	go func() {
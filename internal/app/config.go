@@ -26,10 +26,13 @@ var (
 //
 // nolint:govet // prefer readability over field alignment optimization for this case.
 type Configuration struct {
-	// LogLevel is the app verbose logging level.
-	// one of - info, debug, trace
+	// LogLevel is the app verbose logging level, the --log-level flag takes precedence.
+	// one of - trace, debug, info, warn, error
 	LogLevel string `mapstructure:"log_level"`
 
+	// Logging defines the log format and the per package log levels.
+	Logging *LoggingOptions `mapstructure:"logging"`
+
 	// AppKind is the application kind - worker / client
 	AppKind model.AppKind `mapstructure:"app_kind"`
 
@@ -54,6 +57,105 @@ type Configuration struct {
 
 	// OrchestratorAPIParams required for inband run mode
 	OrchestratorAPIParams *OrchestratorAPIParams `mapstructure:"orchestrator_api"`
+
+	// OutofbandOptions defines parameters for out-of-band firmware installs.
+	OutofbandOptions *OutofbandOptions `mapstructure:"outofband"`
+
+	// Redaction defines the sensitive values redacted from logs, traces and the task status.
+	Redaction *RedactionOptions `mapstructure:"redaction"`
+
+	// StatusHistory defines how the task status messages are compacted in the published task status.
+	StatusHistory *StatusHistoryOptions `mapstructure:"status_history"`
+
+	// TaskLog defines how the log of each task is captured.
+	TaskLog *TaskLogOptions `mapstructure:"task_log"`
+
+	// TaskTimeout is the timeout for tasks which do not include a timeout parameter, zero is no timeout.
+	TaskTimeout time.Duration `mapstructure:"task_timeout"`
+}
+
+// TaskLogOptions defines the task log capture parameters, unset values fall back to defaults.
+type TaskLogOptions struct {
+	// MaxBytes caps the size of the captured task log.
+	MaxBytes int `mapstructure:"max_bytes"`
+
+	// Dir when set, the task log is written to a <condition ID>.log file in this directory.
+	Dir string `mapstructure:"dir"`
+
+	// DisablePublish disables publishing the task log with the final task state.
+	DisablePublish bool `mapstructure:"disable_publish"`
+}
+
+// StatusHistoryOptions defines the task status history size budget, unset values fall back to defaults.
+type StatusHistoryOptions struct {
+	// MaxBytes is the size budget for the status messages published with the task status.
+	MaxBytes int `mapstructure:"max_bytes"`
+
+	// Head is the number of earliest status messages always kept.
+	Head int `mapstructure:"head"`
+
+	// SpillDir when set, the full status history of each task is written to a log file in this directory.
+	SpillDir string `mapstructure:"spill_dir"`
+}
+
+// LoggingOptions defines the log format and the log levels for the package loggers.
+type LoggingOptions struct {
+	// Format is the log format - one of json, text.
+	Format string `mapstructure:"format"`
+
+	// Packages overrides the log level for the package loggers - worker, ctrl, bmclib, otel.
+	Packages map[string]string `mapstructure:"packages"`
+}
+
+// RedactionOptions defines the patterns redacted in addition to the default patterns.
+type RedactionOptions struct {
+	// Patterns are regular expressions, when a pattern includes the named group 'secret'
+	// only the group is redacted, else the whole match is redacted.
+	Patterns []string `mapstructure:"patterns"`
+
+	// KeepHosts disables redacting BMC addresses from traces and the task status.
+	KeepHosts bool `mapstructure:"keep_hosts"`
+}
+
+// OutofbandOptions defines configuration for out-of-band firmware installs.
+type OutofbandOptions struct {
+	// PollBackoff defines the parameters when polling the BMC for firmware task status.
+	PollBackoff *PollBackoffOptions `mapstructure:"poll_backoff"`
+
+	// BMCLimits defines the per BMC request rate and circuit breaker parameters shared across tasks.
+	BMCLimits *BMCLimitsOptions `mapstructure:"bmc_limits"`
+
+	// TLS defines the BMC certificate verification parameters keyed by facility code.
+	//
+	// BMC certificates are not verified for a facility that is not included, or with insecure_skip_verify set.
+	TLS map[string]*BMCTLSOptions `mapstructure:"tls"`
+}
+
+// BMCTLSOptions defines the BMC certificate verification parameters for a facility.
+type BMCTLSOptions struct {
+	InsecureSkipVerify bool   `mapstructure:"insecure_skip_verify"`
+	CABundle           string `mapstructure:"ca_bundle"`
+	PinStore           string `mapstructure:"pin_store"`
+	TrustOnFirstUse    bool   `mapstructure:"trust_on_first_use"`
+}
+
+// BMCLimitsOptions defines the per BMC request rate limit and circuit breaker parameters,
+// unset values fall back to defaults.
+type BMCLimitsOptions struct {
+	RequestsPerSecond float64       `mapstructure:"requests_per_second"`
+	Burst             int           `mapstructure:"burst"`
+	FailureThreshold  int           `mapstructure:"failure_threshold"`
+	Cooldown          time.Duration `mapstructure:"cooldown"`
+}
+
+// PollBackoffOptions defines the jittered exponential backoff parameters
+// when polling the BMC for firmware task status, unset values fall back to defaults.
+type PollBackoffOptions struct {
+	Min           time.Duration `mapstructure:"min"`
+	Max           time.Duration `mapstructure:"max"`
+	Factor        float64       `mapstructure:"factor"`
+	Timeout       time.Duration `mapstructure:"timeout"`
+	DisableJitter bool          `mapstructure:"disable_jitter"`
 }
 
 // FleetDBAPIOptions defines configuration for the FleetDBAPI client.
@@ -65,6 +167,7 @@ type FleetDBAPIOptions struct {
 	OidcIssuerEndpoint     string   `mapstructure:"oidc_issuer_endpoint"`
 	OidcAudienceEndpoint   string   `mapstructure:"oidc_audience_endpoint"`
 	OidcClientSecret       string   `mapstructure:"oidc_client_secret"`
+	OidcClientSecretRef    string   `mapstructure:"oidc_client_secret_ref"`
 	OidcClientID           string   `mapstructure:"oidc_client_id"`
 	OutofbandFirmwareNS    string   `mapstructure:"outofband_firmware_ns"`
 	AssetStateAttributeNS  string   `mapstructure:"device_state_attribute_ns"`
@@ -78,6 +181,7 @@ type OrchestratorAPIParams struct {
 	OidcIssuerEndpoint   string   `mapstructure:"oidc_issuer_endpoint"`
 	OidcAudienceEndpoint string   `mapstructure:"oidc_audience_endpoint"`
 	OidcClientSecret     string   `mapstructure:"oidc_client_secret"`
+	OidcClientSecretRef  string   `mapstructure:"oidc_client_secret_ref"`
 	OidcClientID         string   `mapstructure:"oidc_client_id"`
 	OidcClientScopes     []string `mapstructure:"oidc_client_scopes"`
 	Endpoint             string   `mapstructure:"endpoint"`
@@ -96,6 +200,14 @@ func (a *App) LoadConfiguration(cfgFile string, storeKind model.StoreKind) error
 	// these are initialized here so viper can read in configuration from env vars
 	// once https://github.com/spf13/viper/pull/1429 is merged, this can go.
 	a.Config.FleetDBAPIOptions = &FleetDBAPIOptions{}
+	a.Config.OutofbandOptions = &OutofbandOptions{
+		PollBackoff: &PollBackoffOptions{},
+		BMCLimits:   &BMCLimitsOptions{},
+	}
+	a.Config.Redaction = &RedactionOptions{}
+	a.Config.Logging = &LoggingOptions{}
+	a.Config.StatusHistory = &StatusHistoryOptions{}
+	a.Config.TaskLog = &TaskLogOptions{}
 
 	if cfgFile != "" {
 		fh, err := os.Open(cfgFile)
@@ -108,8 +220,6 @@ func (a *App) LoadConfiguration(cfgFile string, storeKind model.StoreKind) error
 		}
 	}
 
-	a.v.SetDefault("log.level", "info")
-
 	if err := a.envBindVars(); err != nil {
 		return errors.Wrap(ErrConfig, "env var bind error:"+err.Error())
 	}
@@ -130,7 +240,7 @@ func (a *App) LoadConfiguration(cfgFile string, storeKind model.StoreKind) error
 		a.Config.Concurrency = WorkerConcurrency
 	}
 
-	if a.Mode == model.RunInband {
+	if a.Mode == model.RunInband && a.Kind == model.AppKindWorker {
 		if err := a.inbandInstallParams(); err != nil {
 			return errors.Wrap(ErrConfig, err.Error())
 		}
@@ -266,8 +376,12 @@ func (a *App) envVarOrchestratorAPIOverrides() error {
 		cfg.OidcClientSecret = a.v.GetString("orchestrator.api.oidc.client.secret")
 	}
 
-	if cfg.OidcClientSecret == "" {
-		return errors.New("orchestrator.api.oidc.client.secret not defined")
+	if a.v.GetString("orchestrator.api.oidc.client.secret.ref") != "" {
+		cfg.OidcClientSecretRef = a.v.GetString("orchestrator.api.oidc.client.secret.ref")
+	}
+
+	if cfg.OidcClientSecret == "" && cfg.OidcClientSecretRef == "" {
+		return errors.New("orchestrator.api.oidc.client.secret or orchestrator.api.oidc.client.secret.ref not defined")
 	}
 
 	if a.v.GetString("orchestrator.api.oidc.client.id") != "" {
@@ -336,8 +450,12 @@ func (a *App) envVarServerserviceOverrides() error {
 		a.Config.FleetDBAPIOptions.OidcClientSecret = a.v.GetString("serverservice.oidc.client.secret")
 	}
 
-	if a.Config.FleetDBAPIOptions.OidcClientSecret == "" {
-		return errors.New("serverservice.oidc.client.secret not defined")
+	if a.v.GetString("serverservice.oidc.client.secret.ref") != "" {
+		a.Config.FleetDBAPIOptions.OidcClientSecretRef = a.v.GetString("serverservice.oidc.client.secret.ref")
+	}
+
+	if a.Config.FleetDBAPIOptions.OidcClientSecret == "" && a.Config.FleetDBAPIOptions.OidcClientSecretRef == "" {
+		return errors.New("serverservice.oidc.client.secret or serverservice.oidc.client.secret.ref not defined")
 	}
 
 	if a.v.GetString("serverservice.oidc.client.id") != "" {
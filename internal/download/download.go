@@ -12,6 +12,7 @@ import (
 	"time"
 
 	retryablehttp "github.com/hashicorp/go-retryablehttp"
+	"github.com/metal-toolbox/flasher/internal/model"
 	"github.com/pkg/errors"
 )
 
@@ -25,6 +26,11 @@ var (
 	ErrFormat   = errors.New("bad checksum format")
 )
 
+func init() {
+	model.RegisterFailureCode(model.FailureDownload, ErrDownload)
+	model.RegisterFailureCode(model.FailureChecksum, ErrChecksum, ErrFormat)
+}
+
 // FromURLToFile fetches the file into dst
 func FromURLToFile(ctx context.Context, fileURL, dst string) error {
 	// create file
@@ -52,7 +58,7 @@ func FromURLToFile(ctx context.Context, fileURL, dst string) error {
 
 	resp, err := client.Do(requestRetryable)
 	if err != nil {
-		return err
+		return errors.Wrap(ErrDownload, err.Error())
 	}
 	defer resp.Body.Close()
 
@@ -61,9 +67,11 @@ func FromURLToFile(ctx context.Context, fileURL, dst string) error {
 		return errors.Wrap(ErrDownload, fmt.Sprintf("URL: %s, status code %s", fileURL, resp.Status))
 	}
 
-	_, err = io.Copy(fileHandle, resp.Body)
+	if _, err = io.Copy(fileHandle, resp.Body); err != nil {
+		return errors.Wrap(ErrDownload, err.Error())
+	}
 
-	return err
+	return nil
 }
 
 func ChecksumValidate(filename, checksum string) error {
@@ -1,10 +1,14 @@
 package download
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"testing"
 
+	"github.com/metal-toolbox/flasher/internal/model"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -60,6 +64,7 @@ func TestChecksumValidate(t *testing.T) {
 			err = ChecksumValidate(binPath, tt.checksum)
 			if tt.expectedError != nil {
 				assert.ErrorIs(t, err, tt.expectedError)
+				assert.Equal(t, model.FailureChecksum, model.ClassifyFailure(err))
 				return
 			}
 
@@ -68,3 +73,12 @@ func TestChecksumValidate(t *testing.T) {
 	}
 
 }
+
+func TestFromURLToFileFailure(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+
+	err := FromURLToFile(context.Background(), srv.URL+"/bios.bin", filepath.Join(t.TempDir(), "bios.bin"))
+	assert.ErrorIs(t, err, ErrDownload)
+	assert.Equal(t, model.FailureDownload, model.ClassifyFailure(err))
+}
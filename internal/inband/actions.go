@@ -217,18 +217,20 @@ func AssignStepHandlers(action *model.Action, actionCtx *runner.ActionHandlerCon
 func (i *ActionHandler) definitions() model.Steps {
 	return model.Steps{
 		{
-			Name:        checkInstalledFirmware,
-			Group:       PreInstall,
-			Handler:     i.handler.checkCurrentFirmware,
-			Description: "Check firmware currently installed on component",
-			State:       model.StatePending,
+			Name:          checkInstalledFirmware,
+			Group:         PreInstall,
+			Handler:       i.handler.checkCurrentFirmware,
+			Description:   "Check firmware currently installed on component",
+			State:         model.StatePending,
+			Interruptible: true,
 		},
 		{
-			Name:        downloadFirmware,
-			Group:       PreInstall,
-			Handler:     i.handler.downloadFirmware,
-			Description: "Download and verify firmware file checksum.",
-			State:       model.StatePending,
+			Name:          downloadFirmware,
+			Group:         PreInstall,
+			Handler:       i.handler.downloadFirmware,
+			Description:   "Download and verify firmware file checksum.",
+			State:         model.StatePending,
+			Interruptible: true,
 		},
 		{
 			Name:        installFirmware,
@@ -245,11 +247,12 @@ func (i *ActionHandler) definitions() model.Steps {
 			State:       model.StatePending,
 		},
 		{
-			Name:        checkInstalledFirmware,
-			Group:       PostInstall,
-			Handler:     i.handler.checkCurrentFirmware,
-			Description: "Check firmware currently installed on components",
-			State:       model.StatePending,
+			Name:          checkInstalledFirmware,
+			Group:         PostInstall,
+			Handler:       i.handler.checkCurrentFirmware,
+			Description:   "Check firmware currently installed on components",
+			State:         model.StatePending,
+			Interruptible: true,
 		},
 	}
 }
@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/metal-toolbox/flasher/internal/device"
 	"github.com/metal-toolbox/flasher/internal/download"
@@ -33,6 +34,10 @@ var (
 	ErrRequireHostPoweredOff     = errors.New("expected host to be powered off")
 )
 
+func init() {
+	model.RegisterFailureCode(model.FailureVerificationMismatch, ErrInstalledFirmwareNotEqual)
+}
+
 type handler struct {
 	actionCtx     *runner.ActionHandlerContext
 	action        *model.Action
@@ -146,8 +151,11 @@ func (h *handler) downloadFirmware(ctx context.Context) error {
 	file := filepath.Join(dir, h.actionCtx.Firmware.FileName)
 
 	// download firmware file
+	startTS := time.Now()
+
 	err = download.FromURLToFile(ctx, h.actionCtx.Firmware.URL, file)
 	if err != nil {
+		metrics.ObserveDownload(startTS, h.actionCtx.Firmware.Component, h.actionCtx.Firmware.Vendor, err)
 		return err
 	}
 
@@ -160,10 +168,15 @@ func (h *handler) downloadFirmware(ctx context.Context) error {
 				"vendor":    h.actionCtx.Firmware.Vendor,
 			},
 		).Add(float64(fileInfo.Size()))
+
+		h.action.DownloadedBytes = fileInfo.Size()
 	}
 
 	// validate checksum
-	if err := download.ChecksumValidate(file, h.actionCtx.Firmware.Checksum); err != nil {
+	err = download.ChecksumValidate(file, h.actionCtx.Firmware.Checksum)
+	metrics.ObserveDownload(startTS, h.actionCtx.Firmware.Component, h.actionCtx.Firmware.Vendor, err)
+
+	if err != nil {
 		os.RemoveAll(filepath.Dir(file))
 		return err
 	}
@@ -268,8 +281,8 @@ func (h *handler) powerCycleServer(ctx context.Context) error {
 
 	// we must be able to publish a status at this point
 	h.action.HostPowerCycleInitiated = true
-	h.actionCtx.Task.Status.Append("server powercycle flag set, waiting for powercycle")
-	if errPub := h.actionCtx.Publisher.Publish(ctx, h.actionCtx.Task); errPub != nil {
+	h.actionCtx.Task.AppendStatus("server powercycle flag set, waiting for powercycle")
+	if errPub := model.PublishFlush(ctx, h.actionCtx.Publisher, h.actionCtx.Task); errPub != nil {
 		h.logger.WithError(errPub).Info("publish failure")
 		return errPub
 	}
@@ -0,0 +1,328 @@
+package install
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/metal-toolbox/flasher/internal/redact"
+	"github.com/metal-toolbox/flasher/internal/secrets"
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrTargets      = errors.New("error in install targets")
+	ErrReportFormat = errors.New("unsupported report format, expected one of json, csv")
+)
+
+// TargetState is the outcome of the install on a target.
+type TargetState string
+
+const (
+	TargetPending   TargetState = "pending"
+	TargetRunning   TargetState = "running"
+	TargetSucceeded TargetState = "succeeded"
+	TargetFailed    TargetState = "failed"
+	// TargetSkipped indicates the firmware was already installed on the target.
+	TargetSkipped TargetState = "skipped"
+)
+
+// ReportFormat is the format of the fleet install report.
+type ReportFormat string
+
+const (
+	ReportJSON ReportFormat = "json"
+	ReportCSV  ReportFormat = "csv"
+)
+
+// Validate returns ErrReportFormat if the report format is not supported.
+func (f ReportFormat) Validate() error {
+	switch f {
+	case ReportJSON, ReportCSV, "":
+		return nil
+	default:
+		return errors.Wrap(ErrReportFormat, string(f))
+	}
+}
+
+// Target is a server BMC to install firmware on.
+//
+// The User, Pass or CredentialRef when unset default to the credentials in the install parameters.
+type Target struct {
+	Addr          string
+	User          string
+	Pass          string
+	CredentialRef string
+}
+
+// TargetResult is the outcome of the install on a target.
+type TargetResult struct {
+	Addr    string      `json:"addr"`
+	State   TargetState `json:"state"`
+	Error   string      `json:"error,omitempty"`
+	Elapsed string      `json:"elapsed"`
+}
+
+// LoadTargets reads the install targets from a CSV file.
+//
+// The first row is the header, the addr column is required,
+// the optional user, pass and credential_ref columns set the target BMC credentials.
+//
+//	addr,user,credential_ref
+//	192.168.1.1,root,file:///run/secrets/bmc-a
+//	192.168.1.2,,
+func LoadTargets(path string) ([]*Target, error) {
+	fh, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(ErrTargets, err.Error())
+	}
+	defer fh.Close()
+
+	reader := csv.NewReader(fh)
+	reader.TrimLeadingSpace = true
+	reader.Comment = '#'
+
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, errors.Wrap(ErrTargets, err.Error())
+	}
+
+	if len(rows) < 2 {
+		return nil, errors.Wrap(ErrTargets, "expected a header row and at least one target")
+	}
+
+	columns := map[string]int{}
+	for idx, name := range rows[0] {
+		columns[strings.ToLower(strings.TrimSpace(name))] = idx
+	}
+
+	if _, exists := columns["addr"]; !exists {
+		return nil, errors.Wrap(ErrTargets, "header row lacks the addr column")
+	}
+
+	value := func(row []string, column string) string {
+		idx, exists := columns[column]
+		if !exists || idx >= len(row) {
+			return ""
+		}
+
+		return strings.TrimSpace(row[idx])
+	}
+
+	targets := make([]*Target, 0, len(rows)-1)
+	seen := map[string]bool{}
+
+	for idx, row := range rows[1:] {
+		target := &Target{
+			Addr:          value(row, "addr"),
+			User:          value(row, "user"),
+			Pass:          value(row, "pass"),
+			CredentialRef: value(row, "credential_ref"),
+		}
+
+		if target.Addr == "" {
+			return nil, errors.Wrap(ErrTargets, fmt.Sprintf("row %d: addr required", idx+2))
+		}
+
+		if seen[target.Addr] {
+			return nil, errors.Wrap(ErrTargets, fmt.Sprintf("row %d: duplicate addr %s", idx+2, target.Addr))
+		}
+
+		seen[target.Addr] = true
+		targets = append(targets, target)
+	}
+
+	return targets, nil
+}
+
+// InstallFleet runs an install task for each target with upto parallel tasks running concurrently,
+// the params are applied to each target with the target BMC address and credentials.
+//
+// A progress line is written as each target completes along with a periodic summary.
+func (i *Installer) InstallFleet(ctx context.Context, params *Params, targets []*Target, parallel int, progress io.Writer) []*TargetResult {
+	if parallel < 1 {
+		parallel = 1
+	}
+
+	results := make([]*TargetResult, len(targets))
+	for idx, target := range targets {
+		results[idx] = &TargetResult{Addr: target.Addr, State: TargetPending}
+	}
+
+	var mu sync.Mutex
+	var completed int
+
+	summary := func() {
+		mu.Lock()
+		defer mu.Unlock()
+
+		counts := map[TargetState]int{}
+		for _, result := range results {
+			counts[result.State]++
+		}
+
+		fmt.Fprintf(
+			progress,
+			"progress: pending=%d running=%d succeeded=%d failed=%d skipped=%d\n",
+			counts[TargetPending],
+			counts[TargetRunning],
+			counts[TargetSucceeded],
+			counts[TargetFailed],
+			counts[TargetSkipped],
+		)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(10 * time.Second)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				summary()
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	sem := make(chan struct{}, parallel)
+	wg := &sync.WaitGroup{}
+
+	for idx, target := range targets {
+		// acquire a slot, targets not started are failed when the context is canceled
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			mu.Lock()
+			results[idx].State = TargetFailed
+			results[idx].Error = ctx.Err().Error()
+			mu.Unlock()
+
+			continue
+		}
+
+		mu.Lock()
+		results[idx].State = TargetRunning
+		mu.Unlock()
+
+		wg.Add(1)
+
+		go func(result *TargetResult, target *Target) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			startTS := time.Now()
+			state, err := i.installTarget(ctx, params, target)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			result.State = state
+			result.Elapsed = time.Since(startTS).Round(time.Second).String()
+			if err != nil {
+				result.Error = err.Error()
+			}
+
+			completed++
+
+			line := fmt.Sprintf("[%d/%d] %s %s in %s", completed, len(targets), result.Addr, result.State, result.Elapsed)
+			if result.Error != "" {
+				line += ": " + result.Error
+			}
+
+			fmt.Fprintln(progress, line)
+		}(results[idx], target)
+	}
+
+	wg.Wait()
+	close(done)
+	summary()
+
+	return results
+}
+
+func (i *Installer) installTarget(ctx context.Context, params *Params, target *Target) (TargetState, error) {
+	targetParams := *params
+	targetParams.BmcAddr = target.Addr
+
+	if target.User != "" {
+		targetParams.User = target.User
+	}
+
+	switch {
+	case target.Pass != "":
+		targetParams.Pass = target.Pass
+	case target.CredentialRef != "":
+		cred, err := secrets.ResolveCredential(ctx, target.CredentialRef)
+		if err != nil {
+			return TargetFailed, err
+		}
+
+		if target.User == "" && cred.Username != "" {
+			targetParams.User = cred.Username
+		}
+
+		targetParams.Pass = cred.Password
+	}
+
+	if targetParams.User == "" || targetParams.Pass == "" {
+		return TargetFailed, errors.Wrap(ErrTargets, "BMC user and password required")
+	}
+
+	defer redact.Track("", targetParams.Pass)()
+
+	result, err := i.Install(ctx, &targetParams)
+	if err != nil {
+		// the error is included in the report, redact any credentials
+		return TargetFailed, errors.New(string(result.Outcome) + ": " + redact.String(err.Error()))
+	}
+
+	if result.Outcome == OutcomeNoop {
+		return TargetSkipped, nil
+	}
+
+	return TargetSucceeded, nil
+}
+
+// WriteReport writes the fleet install results in the given format.
+func WriteReport(w io.Writer, format ReportFormat, results []*TargetResult) error {
+	if err := format.Validate(); err != nil {
+		return err
+	}
+
+	switch format {
+	case ReportJSON, "":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+
+		return enc.Encode(results)
+
+	case ReportCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write([]string{"addr", "state", "elapsed", "error"}); err != nil {
+			return err
+		}
+
+		for _, result := range results {
+			if err := cw.Write([]string{result.Addr, string(result.State), result.Elapsed, result.Error}); err != nil {
+				return err
+			}
+		}
+
+		cw.Flush()
+
+		return cw.Error()
+	}
+
+	return nil
+}
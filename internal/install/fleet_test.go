@@ -0,0 +1,130 @@
+package install
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadTargets(t *testing.T) {
+	tests := []struct {
+		name    string
+		csv     string
+		want    []*Target
+		wantErr string
+	}{
+		{
+			name: "targets",
+			csv: "addr, user, credential_ref\n" +
+				"# lab servers\n" +
+				"192.168.1.1, root, file:///run/secrets/bmc-a\n" +
+				"192.168.1.2,,\n",
+			want: []*Target{
+				{Addr: "192.168.1.1", User: "root", CredentialRef: "file:///run/secrets/bmc-a"},
+				{Addr: "192.168.1.2"},
+			},
+		},
+		{
+			name:    "addr column required",
+			csv:     "host,user\n192.168.1.1,root\n",
+			wantErr: "header row lacks the addr column",
+		},
+		{
+			name:    "no targets",
+			csv:     "addr\n",
+			wantErr: "expected a header row and at least one target",
+		},
+		{
+			name:    "duplicate",
+			csv:     "addr\n192.168.1.1\n192.168.1.1\n",
+			wantErr: "row 3: duplicate addr 192.168.1.1",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "targets.csv")
+			require.Nil(t, os.WriteFile(path, []byte(tc.csv), 0o600))
+
+			got, err := LoadTargets(path)
+			if tc.wantErr != "" {
+				assert.ErrorIs(t, err, ErrTargets)
+				assert.Contains(t, err.Error(), tc.wantErr)
+				return
+			}
+
+			require.Nil(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestInstallFleet(t *testing.T) {
+	installer := New(logrus.New())
+
+	params := &Params{
+		User:      "root",
+		Component: "bios",
+		Version:   "2.19.6",
+		File:      filepath.Join(t.TempDir(), "missing.bin"),
+	}
+
+	targets := []*Target{
+		{Addr: "192.168.1.1", Pass: "hunter22"},
+		{Addr: "192.168.1.2"},
+	}
+
+	progress := &bytes.Buffer{}
+	results := installer.InstallFleet(context.Background(), params, targets, 2, progress)
+	require.Len(t, results, 2)
+
+	assert.Equal(t, "192.168.1.1", results[0].Addr)
+	assert.Equal(t, TargetFailed, results[0].State)
+	assert.Contains(t, results[0].Error, "unable to read firmware file")
+
+	assert.Equal(t, TargetFailed, results[1].State)
+	assert.Contains(t, results[1].Error, "BMC user and password required")
+
+	assert.Contains(t, progress.String(), "progress: pending=0 running=0 succeeded=0 failed=2 skipped=0")
+}
+
+func TestInstallFleetCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	targets := []*Target{{Addr: "192.168.1.1"}, {Addr: "192.168.1.2"}, {Addr: "192.168.1.3"}}
+
+	results := New(logrus.New()).InstallFleet(ctx, &Params{}, targets, 1, &bytes.Buffer{})
+	for _, result := range results {
+		assert.Equal(t, TargetFailed, result.State)
+	}
+}
+
+func TestWriteReport(t *testing.T) {
+	results := []*TargetResult{
+		{Addr: "192.168.1.1", State: TargetSucceeded, Elapsed: "5m2s"},
+		{Addr: "192.168.1.2", State: TargetFailed, Elapsed: "1s", Error: "login failed, 401"},
+	}
+
+	buf := &bytes.Buffer{}
+	require.Nil(t, WriteReport(buf, ReportCSV, results))
+
+	want := "addr,state,elapsed,error\n" +
+		"192.168.1.1,succeeded,5m2s,\n" +
+		"192.168.1.2,failed,1s,\"login failed, 401\"\n"
+	assert.Equal(t, want, buf.String())
+
+	assert.ErrorIs(t, WriteReport(buf, "xml", results), ErrReportFormat)
+}
+
+func TestReportFormatValidate(t *testing.T) {
+	assert.Nil(t, ReportJSON.Validate())
+	assert.Nil(t, ReportCSV.Validate())
+	assert.ErrorIs(t, ReportFormat("xml").Validate(), ErrReportFormat)
+}
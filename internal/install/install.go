@@ -2,13 +2,15 @@ package install
 
 import (
 	"context"
-	"log"
 	"net"
+	"net/url"
 	"os"
+	"path"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/metal-toolbox/flasher/internal/model"
+	"github.com/metal-toolbox/flasher/internal/redact"
 	"github.com/metal-toolbox/flasher/internal/runner"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
@@ -17,6 +19,10 @@ import (
 	rtypes "github.com/metal-toolbox/rivets/v2/types"
 )
 
+var (
+	ErrInstall = errors.New("error installing firmware")
+)
+
 type Installer struct {
 	logger *logrus.Logger
 }
@@ -31,36 +37,47 @@ type Params struct {
 	Pass      string
 	Component string
 	File      string
-	Version   string
-	Vendor    string
-	Model     string
+	// URL is the firmware download URL in place of the File, the Checksum is required when set.
+	URL      string
+	Checksum string
+	Version  string
+	Vendor   string
+	Model    string
+	// Firmwares are installed in a single task when set,
+	// in place of the single firmware Component, File, Version, Vendor, Model parameters.
+	Firmwares []*Firmware
 	DryRun    bool
 	Force     bool
 	OnlyPlan  bool
+	// Publisher when set is invoked on each task status update.
+	Publisher model.Publisher
+	// StatusFile when set is written with the task status record once the task completes.
+	StatusFile string
 }
 
-func (i *Installer) Install(ctx context.Context, params *Params) {
-	_, err := os.Stat(params.File)
-	if err != nil {
-		log.Fatal(errors.Wrap(err, "unable to read firmware file"))
-	}
-
-	taskParams := &rctypes.FirmwareInstallTaskParameters{
-		ForceInstall: params.Force,
-		DryRun:       params.DryRun,
-		Firmwares: []rctypes.Firmware{
-			{
-				Component: params.Component,
-				Version:   params.Version,
-				Models:    []string{params.Model},
-				Vendor:    params.Vendor,
-			},
-		},
+// Install runs a firmware install task for the server,
+// the result is returned with the task outcome and the state of each action, step.
+func (i *Installer) Install(ctx context.Context, params *Params) (*Result, error) {
+	startedAt := time.Now()
+
+	defer redact.Track("", params.Pass)()
+
+	// result for errors before the task is run, with the credentials redacted
+	failed := func(err error) (*Result, error) {
+		return &Result{
+			BMCAddr:     params.BmcAddr,
+			Outcome:     OutcomeFailed,
+			Error:       redact.String(err.Error()),
+			StartedAt:   startedAt,
+			CompletedAt: time.Now(),
+			Elapsed:     time.Since(startedAt).Round(time.Millisecond).String(),
+			Actions:     []*ActionResult{},
+		}, err
 	}
 
-	task, err := model.NewTask(uuid.New(), rctypes.FirmwareInstall, taskParams)
+	task, files, err := newTask(params)
 	if err != nil {
-		i.logger.Fatal(err)
+		return failed(err)
 	}
 
 	task.Parameters.DryRun = params.DryRun
@@ -68,8 +85,12 @@ func (i *Installer) Install(ctx context.Context, params *Params) {
 		BMCAddress:  net.ParseIP(params.BmcAddr).String(),
 		BMCUser:     params.User,
 		BMCPassword: params.Pass,
-		Model:       params.Model,
-		Vendor:      params.Vendor,
+	}
+
+	// the server vendor, model are otherwise set from the device inventory
+	if len(params.Firmwares) == 0 {
+		task.Server.Model = params.Model
+		task.Server.Vendor = params.Vendor
 	}
 
 	task.Status = rctypes.NewTaskStatusRecord("initialized task")
@@ -78,19 +99,96 @@ func (i *Installer) Install(ctx context.Context, params *Params) {
 		logrus.Fields{
 			"dry-run":   params.DryRun,
 			"bmc":       params.BmcAddr,
-			"component": params.Component,
+			"firmwares": len(task.Parameters.Firmwares),
 		})
 
-	i.runTask(ctx, params, &task, le)
+	err = i.runTask(ctx, params, files, task, le)
+
+	if params.StatusFile != "" {
+		if errStatus := WriteStatus(params.StatusFile, &task.Status); errStatus != nil {
+			le.WithError(errStatus).Warn("task status write error")
+		}
+	}
+
+	return newResult(task, startedAt, err), err
 }
 
-func (i *Installer) runTask(ctx context.Context, params *Params, task *model.Task, le *logrus.Entry) {
+// newTask returns the install task for the parameters, along with the local firmware files
+// indexed by the task firmware parameters - an empty value indicates the firmware is downloaded from its URL.
+func newTask(params *Params) (*model.Task, []string, error) {
+	firmwares := params.Firmwares
+	if len(firmwares) == 0 {
+		firmwares = []*Firmware{
+			{
+				Component: params.Component,
+				Version:   params.Version,
+				Model:     params.Model,
+				Vendor:    params.Vendor,
+				File:      params.File,
+				URL:       params.URL,
+				Checksum:  params.Checksum,
+			},
+		}
+	}
+
+	taskParams := &rctypes.FirmwareInstallTaskParameters{
+		ForceInstall: params.Force,
+		DryRun:       params.DryRun,
+	}
+
+	// the local firmware files, indexed by the task firmware parameters
+	files := make([]string, 0, len(firmwares))
+
+	for _, fw := range firmwares {
+		if err := fw.validate(); err != nil {
+			return nil, nil, errors.Wrap(ErrInstall, fw.Component+": "+err.Error())
+		}
+
+		if fw.File != "" {
+			if _, err := os.Stat(fw.File); err != nil {
+				return nil, nil, errors.Wrap(ErrInstall, "unable to read firmware file: "+err.Error())
+			}
+		}
+
+		// the file name is read from the URL path, excluding any query string (pre-signed URLs) or fragment
+		var fileName string
+		if fw.URL != "" {
+			u, err := url.Parse(fw.URL)
+			if err != nil {
+				return nil, nil, errors.Wrap(ErrInstall, fw.Component+": invalid firmware url: "+err.Error())
+			}
+
+			fileName = path.Base(u.Path)
+		}
+
+		taskParams.Firmwares = append(taskParams.Firmwares, rctypes.Firmware{
+			Component: fw.Component,
+			Version:   fw.Version,
+			Models:    []string{fw.Model},
+			Vendor:    fw.Vendor,
+			URL:       fw.URL,
+			FileName:  fileName,
+			Checksum:  fw.Checksum,
+		})
+
+		files = append(files, fw.File)
+	}
+
+	task, err := model.NewTask(uuid.New(), rctypes.FirmwareInstall, taskParams)
+	if err != nil {
+		return nil, nil, errors.Wrap(ErrInstall, err.Error())
+	}
+
+	return &task, files, nil
+}
+
+func (i *Installer) runTask(ctx context.Context, params *Params, files []string, task *model.Task, le *logrus.Entry) error {
 	h := &handler{
-		fwFile:   params.File,
+		fwFiles:  files,
 		onlyPlan: params.OnlyPlan,
 		taskCtx: &runner.TaskHandlerContext{
 			Task:      task,
-			Publisher: nil,
+			Publisher: params.Publisher,
 			Logger:    le,
 		},
 	}
@@ -99,21 +197,23 @@ func (i *Installer) runTask(ctx context.Context, params *Params, task *model.Tas
 
 	startTS := time.Now()
 
-	i.logger.Info("running task for device")
+	le.Info("running task for device")
 
 	if err := r.RunTask(ctx, task, h); err != nil {
-		i.logger.WithFields(
+		le.WithFields(
 			logrus.Fields{
 				"bmc-ip": task.Server.BMCAddress,
 				"err":    err.Error(),
 			},
 		).Warn("task for device failed")
 
-		return
+		return errors.Wrap(ErrInstall, err.Error())
 	}
 
-	i.logger.WithFields(logrus.Fields{
+	le.WithFields(logrus.Fields{
 		"bmc-ip":  task.Server.BMCAddress,
 		"elapsed": time.Since(startTS).String(),
 	}).Info("task for device completed")
+
+	return nil
 }
@@ -0,0 +1,175 @@
+package install
+
+import (
+	"context"
+	"crypto/md5"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	bconsts "github.com/metal-toolbox/bmclib/constants"
+	"github.com/metal-toolbox/flasher/internal/device"
+	"github.com/metal-toolbox/flasher/internal/download"
+	"github.com/metal-toolbox/flasher/internal/model"
+	"github.com/metal-toolbox/flasher/internal/runner"
+	rtypes "github.com/metal-toolbox/rivets/v2/types"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstallParams(t *testing.T) {
+	tests := []struct {
+		name    string
+		params  *Params
+		wantErr string
+	}{
+		{
+			name:    "file or url required",
+			params:  &Params{Component: "bios", Version: "2.19.6"},
+			wantErr: "bios: one of file or url required",
+		},
+		{
+			name:    "checksum required with url",
+			params:  &Params{Component: "bios", Version: "2.19.6", URL: "https://example.com/BIOS.EXE"},
+			wantErr: "bios: checksum required with url",
+		},
+		{
+			name:    "file and url",
+			params:  &Params{Component: "bios", Version: "2.19.6", File: "/tmp/BIOS.EXE", URL: "https://example.com/BIOS.EXE"},
+			wantErr: "bios: file and url are mutually exclusive",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := New(logrus.New()).Install(context.Background(), tc.params)
+			assert.ErrorIs(t, err, ErrInstall)
+			assert.Contains(t, err.Error(), tc.wantErr)
+		})
+	}
+}
+
+func TestNewTaskFileName(t *testing.T) {
+	task, _, err := newTask(&Params{
+		Component: "bios",
+		Version:   "2.19.6",
+		URL:       "https://example.com/firmware/BIOS_2.19.6.EXE?X-Amz-Signature=abc&X-Amz-Expires=300#frag",
+		Checksum:  "md5sum:4189d3cb123a781d09a4f568bb686b23",
+	})
+	require.Nil(t, err)
+	require.Len(t, task.Parameters.Firmwares, 1)
+	assert.Equal(t, "BIOS_2.19.6.EXE", task.Parameters.Firmwares[0].FileName)
+
+	_, _, err = newTask(&Params{Component: "bios", Version: "2.19.6", URL: "https://example.com/%zz", Checksum: "md5sum:abc"})
+	assert.ErrorIs(t, err, ErrInstall)
+	assert.Contains(t, err.Error(), "invalid firmware url")
+}
+
+func TestInstallParamsErrorRedacted(t *testing.T) {
+	params := &Params{Component: "bios", Version: "2.19.6", File: "/does/not/exist/hunter22/BIOS.EXE", Pass: "hunter22"}
+
+	result, err := New(logrus.New()).Install(context.Background(), params)
+	assert.ErrorIs(t, err, ErrInstall)
+	assert.Equal(t, OutcomeFailed, result.Outcome)
+	assert.Contains(t, result.Error, "unable to read firmware file")
+	assert.NotContains(t, result.Error, "hunter22")
+}
+
+func TestInstallDownload(t *testing.T) {
+	content := []byte("firmware payload")
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/BIOS.EXE" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		_, _ = w.Write(content)
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name     string
+		checksum string
+		wantErr  error
+	}{
+		{
+			name:     "downloaded and verified",
+			checksum: fmt.Sprintf("md5sum:%x", md5.Sum(content)),
+		},
+		{
+			name:     "checksum mismatch",
+			checksum: "md5sum:0123456789abcdef0123456789abcdef",
+			wantErr:  download.ErrChecksum,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// the --url, --checksum flags
+			task, files, err := newTask(&Params{
+				Component: "bios",
+				Version:   "2.19.6",
+				URL:       srv.URL + "/BIOS.EXE",
+				Checksum:  tc.checksum,
+			})
+			require.Nil(t, err)
+
+			task.Server = &rtypes.Server{}
+
+			dq := device.NewMockOutofbandQueryor(t)
+			dq.EXPECT().FirmwareInstallSteps(mock.Anything, "bios").
+				Return([]bconsts.FirmwareInstallStep{
+					bconsts.FirmwareInstallStepUploadInitiateInstall,
+					bconsts.FirmwareInstallStepInstallStatus,
+				}, nil)
+
+			h := &handler{
+				fwFiles: files,
+				taskCtx: &runner.TaskHandlerContext{
+					Task:          task,
+					Logger:        logrus.NewEntry(logrus.New()),
+					DeviceQueryor: dq,
+				},
+			}
+
+			require.Nil(t, h.PlanActions(context.Background()))
+			require.Len(t, task.Data.ActionsPlanned, 1)
+
+			action := task.Data.ActionsPlanned[0]
+			assert.Equal(t, "", action.FirmwareTempFile, "firmware with a URL is downloaded")
+
+			var step *model.Step
+			for _, s := range action.Steps {
+				if s.Name == "downloadFirmware" {
+					step = s
+				}
+			}
+
+			require.NotNil(t, step, "download step planned")
+
+			err = step.Handler(context.Background())
+			if tc.wantErr != nil {
+				assert.ErrorIs(t, err, tc.wantErr)
+				assert.Equal(t, model.FailureChecksum, model.ClassifyFailure(err))
+				assert.Equal(t, "", action.FirmwareTempFile)
+				return
+			}
+
+			require.Nil(t, err)
+			defer os.RemoveAll(filepath.Dir(action.FirmwareTempFile))
+
+			assert.Equal(t, "BIOS.EXE", filepath.Base(action.FirmwareTempFile))
+			assert.Equal(t, int64(len(content)), action.DownloadedBytes)
+
+			got, err := os.ReadFile(action.FirmwareTempFile)
+			require.Nil(t, err)
+			assert.Equal(t, content, got)
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package install
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	ErrManifest = errors.New("error in firmware manifest")
+)
+
+// Firmware is a firmware to be installed, from a local file or downloaded from a URL.
+type Firmware struct {
+	Component string `yaml:"component"`
+	Vendor    string `yaml:"vendor"`
+	Model     string `yaml:"model"`
+	Version   string `yaml:"version"`
+
+	// File is the local firmware file path.
+	File string `yaml:"file"`
+
+	// URL is the firmware download URL, the Checksum is required when set.
+	URL      string `yaml:"url"`
+	Checksum string `yaml:"checksum"`
+}
+
+// Manifest lists the firmware to be installed on a server in a single task.
+//
+//	firmwares:
+//	  - component: bios
+//	    vendor: dell
+//	    model: r6515
+//	    version: 2.19.6
+//	    file: /tmp/BIOS_C4FT0_WN64_2.19.6.EXE
+//	  - component: bmc
+//	    vendor: dell
+//	    model: r6515
+//	    version: 7.00.00.00
+//	    url: https://dl.dell.com/FOLDER08105057M/1/iDRAC_7.00.00.00.EXE
+//	    checksum: md5sum:1ddcb3c3d0fc5925ef03a3dde768e9e2
+type Manifest struct {
+	Firmwares []*Firmware `yaml:"firmwares"`
+}
+
+// LoadManifest reads and validates the firmware manifest, the manifest is YAML or JSON.
+func LoadManifest(path string) (*Manifest, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(ErrManifest, err.Error())
+	}
+
+	manifest := &Manifest{}
+	if err := yaml.Unmarshal(b, manifest); err != nil {
+		return nil, errors.Wrap(ErrManifest, err.Error())
+	}
+
+	if len(manifest.Firmwares) == 0 {
+		return nil, errors.Wrap(ErrManifest, "no firmwares listed")
+	}
+
+	for idx, fw := range manifest.Firmwares {
+		if err := fw.validate(); err != nil {
+			return nil, errors.Wrap(ErrManifest, fmt.Sprintf("firmwares[%d]: %s", idx, err.Error()))
+		}
+	}
+
+	return manifest, nil
+}
+
+func (f *Firmware) validate() error {
+	switch {
+	case f == nil:
+		return errors.New("empty entry")
+	case f.Component == "":
+		return errors.New("component required")
+	case f.Version == "":
+		return errors.New("version required")
+	case f.File == "" && f.URL == "":
+		return errors.New("one of file or url required")
+	case f.File != "" && f.URL != "":
+		return errors.New("file and url are mutually exclusive")
+	case f.URL != "" && f.Checksum == "":
+		return errors.New("checksum required with url")
+	}
+
+	return nil
+}
@@ -0,0 +1,99 @@
+package install
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadManifest(t *testing.T) {
+	tests := []struct {
+		name     string
+		manifest string
+		want     *Manifest
+		wantErr  string
+	}{
+		{
+			name: "yaml",
+			manifest: `
+firmwares:
+  - component: bios
+    vendor: dell
+    model: r6515
+    version: 2.19.6
+    file: /tmp/BIOS.EXE
+  - component: bmc
+    vendor: dell
+    model: r6515
+    version: 7.00.00.00
+    url: https://example.com/iDRAC.EXE
+    checksum: md5sum:1ddcb3c3d0fc5925ef03a3dde768e9e2
+`,
+			want: &Manifest{
+				Firmwares: []*Firmware{
+					{Component: "bios", Vendor: "dell", Model: "r6515", Version: "2.19.6", File: "/tmp/BIOS.EXE"},
+					{
+						Component: "bmc",
+						Vendor:    "dell",
+						Model:     "r6515",
+						Version:   "7.00.00.00",
+						URL:       "https://example.com/iDRAC.EXE",
+						Checksum:  "md5sum:1ddcb3c3d0fc5925ef03a3dde768e9e2",
+					},
+				},
+			},
+		},
+		{
+			name:     "json",
+			manifest: `{"firmwares": [{"component": "nic", "version": "1.2.3", "file": "/tmp/NIC.EXE"}]}`,
+			want: &Manifest{
+				Firmwares: []*Firmware{{Component: "nic", Version: "1.2.3", File: "/tmp/NIC.EXE"}},
+			},
+		},
+		{
+			name:     "empty",
+			manifest: `firmwares: []`,
+			wantErr:  "no firmwares listed",
+		},
+		{
+			name:     "version required",
+			manifest: `{"firmwares": [{"component": "nic", "file": "/tmp/NIC.EXE"}]}`,
+			wantErr:  "firmwares[0]: version required",
+		},
+		{
+			name:     "file or url required",
+			manifest: `{"firmwares": [{"component": "nic", "version": "1.2.3"}]}`,
+			wantErr:  "one of file or url required",
+		},
+		{
+			name:     "file and url",
+			manifest: `{"firmwares": [{"component": "nic", "version": "1.2.3", "file": "/tmp/NIC.EXE", "url": "https://example.com/NIC.EXE"}]}`,
+			wantErr:  "file and url are mutually exclusive",
+		},
+		{
+			name:     "checksum required with url",
+			manifest: `{"firmwares": [{"component": "nic", "version": "1.2.3", "url": "https://example.com/NIC.EXE"}]}`,
+			wantErr:  "checksum required with url",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "manifest.yaml")
+			require.Nil(t, os.WriteFile(path, []byte(tc.manifest), 0o600))
+
+			got, err := LoadManifest(path)
+			if tc.wantErr != "" {
+				assert.ErrorIs(t, err, ErrManifest)
+				assert.Contains(t, err.Error(), tc.wantErr)
+				return
+			}
+
+			require.Nil(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
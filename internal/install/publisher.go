@@ -0,0 +1,142 @@
+package install
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/metal-toolbox/flasher/internal/model"
+	"github.com/metal-toolbox/flasher/internal/redact"
+	rctypes "github.com/metal-toolbox/rivets/v2/condition"
+)
+
+const (
+	// clears the current terminal line
+	clearLine = "\r\033[K"
+)
+
+// TerminalPublisher implements the model.Publisher interface to render the install progress,
+// the current action, step, BMC job status and the elapsed time.
+//
+// On a terminal the progress is rendered on a single line updated in place,
+// otherwise a line is written each time the progress changes.
+type TerminalPublisher struct {
+	mu      sync.Mutex
+	w       io.Writer
+	tty     bool
+	started time.Time
+	last    string
+}
+
+// NewTerminalPublisher returns a TerminalPublisher writing to w.
+func NewTerminalPublisher(w io.Writer, tty bool) *TerminalPublisher {
+	return &TerminalPublisher{w: w, tty: tty, started: time.Now()}
+}
+
+// IsTerminal returns true when the file is a terminal.
+func IsTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	if err != nil {
+		return false
+	}
+
+	return fi.Mode()&os.ModeCharDevice != 0
+}
+
+// Publish renders the task progress.
+func (p *TerminalPublisher) Publish(_ context.Context, task *model.Task) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	progress := redact.String(p.progress(task))
+	elapsed := time.Since(p.started).Round(time.Second)
+
+	if p.tty {
+		_, err := fmt.Fprintf(p.w, "%s[%s] %s", clearLine, elapsed, progress)
+		return err
+	}
+
+	if progress == p.last {
+		return nil
+	}
+
+	p.last = progress
+
+	_, err := fmt.Fprintf(p.w, "[%s] %s\n", elapsed, progress)
+
+	return err
+}
+
+// Done ends the progress line on a terminal.
+func (p *TerminalPublisher) Done() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.tty {
+		fmt.Fprintln(p.w)
+	}
+}
+
+// progress returns the current action, step and the last status.
+func (p *TerminalPublisher) progress(task *model.Task) string {
+	var status string
+	if len(task.Status.StatusMsgs) > 0 {
+		status = task.Status.Last()
+	}
+
+	actions := task.Data.ActionsPlanned
+	if len(actions) == 0 || rctypes.StateIsComplete(task.State) {
+		return fmt.Sprintf("task %s: %s", task.State, status)
+	}
+
+	// the active action, or the last action completed
+	idx := 0
+	for i, action := range actions {
+		if action.State == model.StatePending {
+			break
+		}
+
+		idx = i
+	}
+
+	action := actions[idx]
+
+	step := "-"
+	for _, s := range action.Steps {
+		if s.State == model.StateActive {
+			step = string(s.Name)
+			break
+		}
+	}
+
+	return fmt.Sprintf(
+		"action %d/%d %s %s %s, step: %s, status: %s",
+		idx+1,
+		len(actions),
+		action.Firmware.Component,
+		action.Firmware.Version,
+		action.State,
+		step,
+		status,
+	)
+}
+
+// WriteStatus writes the task status record as JSON to the file, with the credentials redacted.
+func WriteStatus(path string, status *rctypes.StatusRecord) error {
+	record := rctypes.StatusRecord{}
+	for _, msg := range status.StatusMsgs {
+		msg.Msg = redact.String(msg.Msg)
+		record.StatusMsgs = append(record.StatusMsgs, msg)
+	}
+
+	b, err := json.MarshalIndent(record, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, append(b, '\n'), 0o600)
+}
@@ -0,0 +1,116 @@
+package install
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	rctypes "github.com/metal-toolbox/rivets/v2/condition"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/metal-toolbox/flasher/internal/model"
+	"github.com/metal-toolbox/flasher/internal/outofband"
+	"github.com/metal-toolbox/flasher/internal/redact"
+)
+
+func progressTask(t *testing.T) *model.Task {
+	t.Helper()
+
+	task, err := model.NewTask(uuid.New(), rctypes.FirmwareInstall, &rctypes.FirmwareInstallTaskParameters{
+		Firmwares: []rctypes.Firmware{{Component: "bios"}, {Component: "bmc"}},
+	})
+	require.Nil(t, err)
+
+	task.State = model.StateActive
+	task.Data.ActionsPlanned = model.Actions{
+		{
+			ID:       "bios-1",
+			State:    model.StateActive,
+			Firmware: rctypes.Firmware{Component: "bios", Version: "2.19.6"},
+			Steps: model.Steps{
+				{Name: "downloadFirmware", Group: outofband.PreInstall, State: model.StateSucceeded},
+				{Name: "uploadFirmwareInitiateInstall", Group: outofband.Install, State: model.StateActive},
+			},
+		},
+		{
+			ID:       "bmc-1",
+			State:    model.StatePending,
+			Firmware: rctypes.Firmware{Component: "bmc", Version: "7.00.00.00"},
+		},
+	}
+
+	task.Status.Append("bmc job: running")
+
+	return &task
+}
+
+func TestTerminalPublisher(t *testing.T) {
+	t.Run("plain lines written on change", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		p := NewTerminalPublisher(buf, false)
+		task := progressTask(t)
+
+		require.Nil(t, p.Publish(context.Background(), task))
+		require.Nil(t, p.Publish(context.Background(), task))
+
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		require.Len(t, lines, 1)
+		assert.Contains(
+			t,
+			lines[0],
+			"action 1/2 bios 2.19.6 active, step: uploadFirmwareInitiateInstall, status: bmc job: running",
+		)
+
+		task.Data.ActionsPlanned[0].State = model.StateSucceeded
+		task.Data.ActionsPlanned[1].State = model.StateActive
+		require.Nil(t, p.Publish(context.Background(), task))
+
+		lines = strings.Split(strings.TrimSpace(buf.String()), "\n")
+		require.Len(t, lines, 2)
+		assert.Contains(t, lines[1], "action 2/2 bmc 7.00.00.00 active, step: -")
+
+		task.State = model.StateSucceeded
+		require.Nil(t, p.Publish(context.Background(), task))
+		assert.Contains(t, buf.String(), "task succeeded: bmc job: running")
+	})
+
+	t.Run("terminal line updated in place", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		p := NewTerminalPublisher(buf, true)
+		task := progressTask(t)
+
+		require.Nil(t, p.Publish(context.Background(), task))
+		require.Nil(t, p.Publish(context.Background(), task))
+		p.Done()
+
+		assert.Equal(t, 2, strings.Count(buf.String(), clearLine))
+		assert.Equal(t, 1, strings.Count(buf.String(), "\n"))
+		assert.True(t, strings.HasSuffix(buf.String(), "\n"))
+	})
+}
+
+func TestWriteStatus(t *testing.T) {
+	defer redact.Track("", "hunter22")()
+
+	status := rctypes.NewTaskStatusRecord("initialized task")
+	status.Append("login failed for root:hunter22")
+
+	path := filepath.Join(t.TempDir(), "status.json")
+	require.Nil(t, WriteStatus(path, &status))
+
+	b, err := os.ReadFile(path)
+	require.Nil(t, err)
+
+	assert.Contains(t, string(b), "initialized task")
+	assert.Contains(t, string(b), "login failed for root:")
+	assert.NotContains(t, string(b), "hunter22")
+
+	fi, err := os.Stat(path)
+	require.Nil(t, err)
+	assert.Equal(t, os.FileMode(0o600), fi.Mode().Perm())
+}
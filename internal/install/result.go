@@ -0,0 +1,168 @@
+package install
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/metal-toolbox/flasher/internal/model"
+	"github.com/metal-toolbox/flasher/internal/outofband"
+	"github.com/metal-toolbox/flasher/internal/redact"
+	rctypes "github.com/metal-toolbox/rivets/v2/condition"
+)
+
+// Outcome is the outcome of the install task.
+type Outcome string
+
+const (
+	OutcomeSucceeded Outcome = "succeeded"
+	// OutcomeNoop indicates the firmware was already installed.
+	OutcomeNoop Outcome = "noop"
+	// OutcomeFailed indicates the install failed before the device was changed, and so is safe to retry.
+	OutcomeFailed Outcome = "failed"
+	// OutcomeNeedsAttention indicates the install failed after the server power state was changed,
+	// or the firmware upload, install or a BMC reset was initiated, the device is to be checked before a retry.
+	OutcomeNeedsAttention Outcome = "needs-attention"
+)
+
+// Exit codes for each Outcome.
+const (
+	ExitSucceeded      = 0
+	ExitFailed         = 1
+	ExitNeedsAttention = 2
+	ExitNoop           = 3
+)
+
+// ExitCode returns the process exit code for the outcome.
+func (o Outcome) ExitCode() int {
+	switch o {
+	case OutcomeSucceeded:
+		return ExitSucceeded
+	case OutcomeNoop:
+		return ExitNoop
+	case OutcomeNeedsAttention:
+		return ExitNeedsAttention
+	default:
+		return ExitFailed
+	}
+}
+
+// Result is the machine readable result of an install task.
+type Result struct {
+	TaskID      string            `json:"task_id,omitempty"`
+	BMCAddr     string            `json:"bmc_addr"`
+	Outcome     Outcome           `json:"outcome"`
+	State       rctypes.State     `json:"state,omitempty"`
+	Error       string            `json:"error,omitempty"`
+	FailureCode model.FailureCode `json:"failure_code,omitempty"`
+	StartedAt   time.Time         `json:"started_at"`
+	CompletedAt time.Time         `json:"completed_at"`
+	Elapsed     string            `json:"elapsed"`
+	Actions     []*ActionResult   `json:"actions"`
+	Status      []string          `json:"status,omitempty"`
+}
+
+// ActionResult is the result of an install action for a firmware.
+type ActionResult struct {
+	ID        string        `json:"id"`
+	Component string        `json:"component"`
+	Version   string        `json:"version"`
+	State     rctypes.State `json:"state"`
+	Elapsed   string        `json:"elapsed,omitempty"`
+	Steps     []*StepResult `json:"steps"`
+}
+
+// StepResult is the result of a step within an install action.
+type StepResult struct {
+	Name     string        `json:"name"`
+	Group    string        `json:"group"`
+	State    rctypes.State `json:"state"`
+	Status   string        `json:"status,omitempty"`
+	Attempts int           `json:"attempts,omitempty"`
+	Elapsed  string        `json:"elapsed,omitempty"`
+}
+
+// WriteResult writes the result as JSON.
+func WriteResult(w io.Writer, result *Result) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(result)
+}
+
+// newResult returns the result for the task run, with the credentials redacted.
+func newResult(task *model.Task, startedAt time.Time, err error) *Result {
+	completedAt := time.Now()
+
+	result := &Result{
+		TaskID:      task.ID.String(),
+		BMCAddr:     task.Server.BMCAddress,
+		State:       task.State,
+		StartedAt:   startedAt,
+		CompletedAt: completedAt,
+		Elapsed:     completedAt.Sub(startedAt).Round(time.Millisecond).String(),
+		Actions:     []*ActionResult{},
+	}
+
+	if err != nil {
+		result.Error = redact.String(err.Error())
+		result.FailureCode = task.Data.FailureCode
+	}
+
+	for _, msg := range task.Status.StatusMsgs {
+		result.Status = append(result.Status, redact.String(msg.Msg))
+	}
+
+	// the device was changed by the install
+	changed := false
+	// the firmware was installed on any component
+	installed := false
+
+	for _, action := range task.Data.ActionsPlanned {
+		ar := &ActionResult{
+			ID:        action.ID,
+			Component: action.Firmware.Component,
+			Version:   action.Firmware.Version,
+			State:     action.State,
+			Elapsed:   action.Elapsed(),
+		}
+
+		for _, step := range action.Steps {
+			ar.Steps = append(ar.Steps, &StepResult{
+				Name:     string(step.Name),
+				Group:    string(step.Group),
+				State:    step.State,
+				Status:   redact.String(step.Status),
+				Attempts: step.Attempts,
+				Elapsed:  step.Elapsed(),
+			})
+
+			if step.State == model.StatePending {
+				continue
+			}
+
+			if outofband.StepChangesDevice(action, step) {
+				changed = true
+			}
+
+			if step.Group == outofband.Install && step.State == model.StateSucceeded {
+				installed = true
+			}
+		}
+
+		result.Actions = append(result.Actions, ar)
+	}
+
+	switch {
+	case err != nil && changed:
+		result.Outcome = OutcomeNeedsAttention
+	case err != nil:
+		result.Outcome = OutcomeFailed
+	case !installed:
+		result.Outcome = OutcomeNoop
+	default:
+		result.Outcome = OutcomeSucceeded
+	}
+
+	return result
+}
@@ -0,0 +1,120 @@
+package install
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	rctypes "github.com/metal-toolbox/rivets/v2/condition"
+	rtypes "github.com/metal-toolbox/rivets/v2/types"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/metal-toolbox/flasher/internal/model"
+	"github.com/metal-toolbox/flasher/internal/outofband"
+)
+
+func TestNewResult(t *testing.T) {
+	steps := func(states ...rctypes.State) model.Steps {
+		names := []model.StepName{"checkInstalledFirmware", "downloadFirmware", "uploadFirmwareInitiateInstall"}
+		groups := []model.StepGroup{outofband.PreInstall, outofband.PreInstall, outofband.Install}
+
+		s := model.Steps{}
+		for idx, state := range states {
+			s = append(s, &model.Step{Name: names[idx], Group: groups[idx], State: state})
+		}
+
+		return s
+	}
+
+	tests := []struct {
+		name       string
+		steps      model.Steps
+		poweredOff bool
+		err        error
+		want       Outcome
+		wantExit   int
+	}{
+		{
+			name:     "succeeded",
+			steps:    steps(model.StateSucceeded, model.StateSucceeded, model.StateSucceeded),
+			want:     OutcomeSucceeded,
+			wantExit: ExitSucceeded,
+		},
+		{
+			name:     "installed firmware equal",
+			steps:    steps(model.StateSucceeded, model.StatePending, model.StatePending),
+			want:     OutcomeNoop,
+			wantExit: ExitNoop,
+		},
+		{
+			name:     "failed before install",
+			steps:    steps(model.StateSucceeded, model.StateFailed, model.StatePending),
+			err:      errors.New("checksum mismatch"),
+			want:     OutcomeFailed,
+			wantExit: ExitFailed,
+		},
+		{
+			name:     "failed on install",
+			steps:    steps(model.StateSucceeded, model.StateSucceeded, model.StateFailed),
+			err:      errors.New("BMC returned 500"),
+			want:     OutcomeNeedsAttention,
+			wantExit: ExitNeedsAttention,
+		},
+		{
+			name: "failed after power off",
+			steps: model.Steps{
+				{Name: "checkInstalledFirmware", Group: outofband.PreInstall, State: model.StateSucceeded},
+				{Name: "powerOffServer", Group: outofband.PowerState, State: model.StateFailed},
+				{Name: "uploadFirmwareInitiateInstall", Group: outofband.Install, State: model.StatePending},
+			},
+			poweredOff: true,
+			err:        errors.New("power off timed out"),
+			want:       OutcomeNeedsAttention,
+			wantExit:   ExitNeedsAttention,
+		},
+		{
+			name: "failed after power state unchanged",
+			steps: model.Steps{
+				{Name: "powerOnServer", Group: outofband.PowerState, State: model.StateSucceeded},
+				{Name: "checkInstalledFirmware", Group: outofband.PreInstall, State: model.StateSucceeded},
+				{Name: "downloadFirmware", Group: outofband.PreInstall, State: model.StateFailed},
+			},
+			err:      errors.New("checksum mismatch"),
+			want:     OutcomeFailed,
+			wantExit: ExitFailed,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			task, err := model.NewTask(uuid.New(), rctypes.FirmwareInstall, &rctypes.FirmwareInstallTaskParameters{
+				Firmwares: []rctypes.Firmware{{Component: "bios", Version: "2.19.6"}},
+			})
+			require.Nil(t, err)
+
+			task.Server = &rtypes.Server{BMCAddress: "192.168.1.1"}
+			task.Data.ActionsPlanned = model.Actions{
+				{
+					ID:                    "bios-0",
+					Firmware:              task.Parameters.Firmwares[0],
+					State:                 model.StateSucceeded,
+					Steps:                 tc.steps,
+					HostPowerOffInitiated: tc.poweredOff,
+				},
+			}
+
+			got := newResult(&task, time.Now(), tc.err)
+			assert.Equal(t, tc.want, got.Outcome)
+			assert.Equal(t, tc.wantExit, got.Outcome.ExitCode())
+			require.Len(t, got.Actions, 1)
+			assert.Len(t, got.Actions[0].Steps, 3)
+			assert.Equal(t, "bios", got.Actions[0].Component)
+
+			if tc.err != nil {
+				assert.Equal(t, tc.err.Error(), got.Error)
+			}
+		})
+	}
+}
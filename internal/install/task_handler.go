@@ -2,6 +2,8 @@ package install
 
 import (
 	"context"
+	"fmt"
+	"strings"
 
 	common "github.com/metal-toolbox/bmc-common"
 	"github.com/metal-toolbox/flasher/internal/device"
@@ -25,8 +27,10 @@ var (
 //
 // The handler is instantiated to run a single task
 type handler struct {
-	taskCtx  *runner.TaskHandlerContext
-	fwFile   string
+	taskCtx *runner.TaskHandlerContext
+	// fwFiles are the local firmware files indexed by the task firmware parameters,
+	// an empty value indicates the firmware is to be downloaded.
+	fwFiles  []string
 	onlyPlan bool
 }
 
@@ -66,39 +70,93 @@ func (t *handler) Query(ctx context.Context) error {
 func (t *handler) PlanActions(ctx context.Context) error {
 	t.taskCtx.Logger.Debug("create the plan")
 
-	param := t.taskCtx.Task.Parameters.Firmwares[0]
+	// the local file for each firmware
+	files := map[*rctypes.Firmware]string{}
 
-	firmware := &rctypes.Firmware{
-		Component: param.Component,
-		Vendor:    param.Vendor,
-		Models:    param.Models,
-		Version:   param.Version,
+	toInstall := []*rctypes.Firmware{}
+	for idx := range t.taskCtx.Task.Parameters.Firmwares {
+		firmware := &t.taskCtx.Task.Parameters.Firmwares[idx]
+		if idx < len(t.fwFiles) {
+			files[firmware] = t.fwFiles[idx]
+		}
+
+		toInstall = append(toInstall, firmware)
 	}
 
-	actionCtx := &runner.ActionHandlerContext{
-		TaskHandlerContext: t.taskCtx,
-		Firmware:           firmware,
-		First:              true,
-		Last:               true,
+	if !t.taskCtx.Task.Parameters.ForceInstall {
+		toInstall = t.removeFirmwareAlreadyAtDesiredVersion(toInstall)
 	}
 
-	aHandler := &outofband.ActionHandler{}
-	action, err := aHandler.ComposeAction(ctx, actionCtx)
-	if err != nil {
-		return err
+	if len(toInstall) == 0 {
+		t.taskCtx.Task.AppendStatus("no firmware installs required")
+		return nil
 	}
 
-	action.FirmwareTempFile = t.fwFile
+	// install in order, the first and last actions handle the host power state for the batch
+	model.SortFirmwareByInstallOrder(toInstall)
+
+	actions := model.Actions{}
+	for idx, firmware := range toInstall {
+		actionCtx := &runner.ActionHandlerContext{
+			TaskHandlerContext: t.taskCtx,
+			Firmware:           firmware,
+			First:              (idx == 0),
+			Last:               (idx == len(toInstall)-1),
+		}
+
+		aHandler := &outofband.ActionHandler{}
+		action, err := aHandler.ComposeAction(ctx, actionCtx)
+		if err != nil {
+			return err
+		}
 
-	//nolint:errcheck  // SetState never returns an error
-	action.SetState(model.StatePending)
+		action.FirmwareTempFile = files[firmware]
 
-	t.taskCtx.Task.Data.ActionsPlanned = []*model.Action{action}
+		action.SetID(t.taskCtx.Task.ID.String(), firmware.Component, idx)
+		//nolint:errcheck  // SetState never returns an error
+		action.SetState(model.StatePending)
+
+		actions = append(actions, action)
+	}
+
+	t.taskCtx.Task.Data.ActionsPlanned = actions
 
 	return nil
 }
 
-func (t *handler) Publish(context.Context) {}
+// removeFirmwareAlreadyAtDesiredVersion skips firmware installed at the requested version,
+// since an action for firmware at the requested version ends the task.
+func (t *handler) removeFirmwareAlreadyAtDesiredVersion(firmwares []*rctypes.Firmware) []*rctypes.Firmware {
+	installed := map[string]string{}
+	for _, cmp := range t.taskCtx.Task.Server.Components {
+		if cmp.Firmware != nil {
+			installed[strings.ToLower(cmp.Name)] = cmp.Firmware.Installed
+		}
+	}
+
+	var toInstall []*rctypes.Firmware
+	for _, fw := range firmwares {
+		if strings.EqualFold(installed[strings.ToLower(fw.Component)], fw.Version) {
+			t.taskCtx.Task.AppendStatus(
+				fmt.Sprintf("[%s] Installed and expected firmware are equal, version=%s", fw.Component, fw.Version),
+			)
+
+			continue
+		}
+
+		toInstall = append(toInstall, fw)
+	}
+
+	return toInstall
+}
+
+// Publish is invoked on each task, action and step state change.
+func (t *handler) Publish(ctx context.Context) {
+	if t.taskCtx.Publisher != nil {
+		//nolint:errcheck // the install is not interrupted on progress output errors
+		_ = t.taskCtx.Publisher.Publish(ctx, t.taskCtx.Task)
+	}
+}
 
 // query device components inventory from the device itself.
 func (t *handler) queryFromDevice(ctx context.Context) ([]*rtypes.Component, error) {
@@ -111,13 +169,13 @@ func (t *handler) queryFromDevice(ctx context.Context) ([]*rtypes.Component, err
 		t.taskCtx.DeviceQueryor = outofband.NewDeviceQueryor(ctx, t.taskCtx.Task.Server, t.taskCtx.Logger)
 	}
 
-	t.taskCtx.Task.Status.Append("connecting to device BMC")
+	t.taskCtx.Task.AppendStatus("connecting to device BMC")
 
 	if err := t.taskCtx.DeviceQueryor.(device.OutofbandQueryor).Open(ctx); err != nil {
 		return nil, err
 	}
 
-	t.taskCtx.Task.Status.Append("collecting inventory from device BMC")
+	t.taskCtx.Task.AppendStatus("collecting inventory from device BMC")
 
 	deviceCommon, err := t.taskCtx.DeviceQueryor.(device.OutofbandQueryor).Inventory(ctx)
 	if err != nil {
@@ -0,0 +1,75 @@
+package install
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	common "github.com/metal-toolbox/bmc-common"
+	bconsts "github.com/metal-toolbox/bmclib/constants"
+	rctypes "github.com/metal-toolbox/rivets/v2/condition"
+	rtypes "github.com/metal-toolbox/rivets/v2/types"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/metal-toolbox/flasher/internal/device"
+	"github.com/metal-toolbox/flasher/internal/model"
+	"github.com/metal-toolbox/flasher/internal/runner"
+)
+
+func TestPlanActions(t *testing.T) {
+	task, err := model.NewTask(uuid.New(), rctypes.FirmwareInstall, &rctypes.FirmwareInstallTaskParameters{
+		Firmwares: []rctypes.Firmware{
+			{Component: "nic", Version: "1.2.3"},
+			{Component: "bios", Version: "2.19.6"},
+			{Component: "drive", Version: "DL6R"},
+			{Component: "bmc", Version: "7.00.00.00", URL: "https://example.com/iDRAC.EXE"},
+		},
+	})
+	require.Nil(t, err)
+
+	task.Server = &rtypes.Server{
+		Components: rtypes.Components{
+			{Name: "bios", Firmware: &common.Firmware{Installed: "2.6.6"}},
+			{Name: "drive", Firmware: &common.Firmware{Installed: "DL6R"}},
+			{Name: "nic", Firmware: &common.Firmware{Installed: "1.2.2"}},
+		},
+	}
+
+	dq := device.NewMockOutofbandQueryor(t)
+	dq.EXPECT().FirmwareInstallSteps(mock.Anything, mock.Anything).
+		Times(3).
+		Return([]bconsts.FirmwareInstallStep{
+			bconsts.FirmwareInstallStepUploadInitiateInstall,
+			bconsts.FirmwareInstallStepInstallStatus,
+		}, nil)
+
+	h := &handler{
+		fwFiles: []string{"/tmp/NIC.EXE", "/tmp/BIOS.EXE", "/tmp/DRIVE.EXE", ""},
+		taskCtx: &runner.TaskHandlerContext{
+			Task:          &task,
+			Logger:        logrus.NewEntry(logrus.New()),
+			DeviceQueryor: dq,
+		},
+	}
+
+	require.Nil(t, h.PlanActions(context.Background()))
+
+	actions := task.Data.ActionsPlanned
+	require.Len(t, actions, 3, "drive firmware at the requested version is skipped")
+
+	// installed in order, with the host power handled by the first, last actions
+	assert.Equal(t, "bmc", actions[0].Firmware.Component)
+	assert.Equal(t, "bios", actions[1].Firmware.Component)
+	assert.Equal(t, "nic", actions[2].Firmware.Component)
+	assert.True(t, actions[0].First)
+	assert.False(t, actions[1].First || actions[1].Last)
+	assert.True(t, actions[2].Last)
+
+	// local files are installed, the bmc firmware is downloaded
+	assert.Equal(t, "", actions[0].FirmwareTempFile)
+	assert.Equal(t, "/tmp/BIOS.EXE", actions[1].FirmwareTempFile)
+	assert.Equal(t, "/tmp/NIC.EXE", actions[2].FirmwareTempFile)
+}
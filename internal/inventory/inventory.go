@@ -0,0 +1,134 @@
+// Package inventory collects the device component inventory as identified by flasher,
+// and writes it out for the CLI.
+package inventory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	common "github.com/metal-toolbox/bmc-common"
+	"github.com/metal-toolbox/flasher/internal/device"
+	"github.com/metal-toolbox/flasher/internal/model"
+	rtypes "github.com/metal-toolbox/rivets/v2/types"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// Format is the output format of the inventory.
+type Format string
+
+const (
+	FormatTable Format = "table"
+	FormatJSON  Format = "json"
+	FormatYAML  Format = "yaml"
+)
+
+var (
+	ErrInventory = errors.New("error collecting device inventory")
+	ErrFormat    = errors.New("unsupported output format, expected one of table, json, yaml")
+)
+
+// Component is a device component as identified by flasher when planning firmware installs.
+type Component struct {
+	Slug     string `json:"slug" yaml:"slug"`
+	Vendor   string `json:"vendor" yaml:"vendor"`
+	Model    string `json:"model" yaml:"model"`
+	Serial   string `json:"serial" yaml:"serial"`
+	Firmware string `json:"firmware" yaml:"firmware"`
+}
+
+// Outofband collects the device inventory through its BMC.
+func Outofband(ctx context.Context, queryor device.OutofbandQueryor) ([]*Component, error) {
+	if err := queryor.Open(ctx); err != nil {
+		return nil, errors.Wrap(ErrInventory, err.Error())
+	}
+
+	// nolint:errcheck // the inventory is collected by this point
+	defer queryor.Close(ctx)
+
+	dev, err := queryor.Inventory(ctx)
+	if err != nil {
+		return nil, errors.Wrap(ErrInventory, err.Error())
+	}
+
+	return FromDevice(dev)
+}
+
+// Inband collects the device inventory from the host.
+func Inband(ctx context.Context, queryor device.InbandQueryor) ([]*Component, error) {
+	dev, err := queryor.Inventory(ctx)
+	if err != nil {
+		return nil, errors.Wrap(ErrInventory, err.Error())
+	}
+
+	return FromDevice(dev)
+}
+
+// FromDevice converts the device inventory into Components,
+// using the same conversion as the firmware install planner.
+func FromDevice(dev *common.Device) ([]*Component, error) {
+	converted, err := model.NewComponentConverter().CommonDeviceToComponents(dev)
+	if err != nil {
+		return nil, err
+	}
+
+	return FromComponents(converted), nil
+}
+
+// FromComponents returns the Components for the rivets components.
+func FromComponents(converted rtypes.Components) []*Component {
+	components := make([]*Component, 0, len(converted))
+	for _, c := range converted {
+		component := &Component{
+			Slug:   c.Name,
+			Vendor: c.Vendor,
+			Model:  c.Model,
+			Serial: c.Serial,
+		}
+
+		if c.Firmware != nil {
+			component.Firmware = c.Firmware.Installed
+		}
+
+		components = append(components, component)
+	}
+
+	return components
+}
+
+// Write writes the components in the given format.
+func Write(w io.Writer, format Format, components []*Component) error {
+	switch format {
+	case FormatTable, "":
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "SLUG\tVENDOR\tMODEL\tSERIAL\tFIRMWARE")
+
+		for _, c := range components {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", c.Slug, c.Vendor, c.Model, c.Serial, c.Firmware)
+		}
+
+		return tw.Flush()
+
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+
+		return enc.Encode(components)
+
+	case FormatYAML:
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+
+		if err := enc.Encode(components); err != nil {
+			return err
+		}
+
+		return enc.Close()
+
+	default:
+		return errors.Wrap(ErrFormat, string(format))
+	}
+}
@@ -0,0 +1,115 @@
+package inventory
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	common "github.com/metal-toolbox/bmc-common"
+	"github.com/metal-toolbox/flasher/internal/device"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func fixtureDevice() *common.Device {
+	dev := common.NewDevice()
+	dev.Model = "PowerEdge R6515"
+	dev.Vendor = "Dell"
+	dev.BIOS = &common.BIOS{
+		Common: common.Common{
+			Serial:   "bios-serial",
+			Firmware: &common.Firmware{Installed: "2.6.6"},
+		},
+	}
+	dev.BMC = &common.BMC{
+		Common: common.Common{
+			Vendor:   "Dell",
+			Model:    "iDRAC9",
+			Serial:   "bmc-serial",
+			Firmware: &common.Firmware{Installed: "6.10.30.00"},
+		},
+	}
+
+	return &dev
+}
+
+func TestOutofband(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("inventory collected", func(t *testing.T) {
+		dq := device.NewMockOutofbandQueryor(t)
+		dq.EXPECT().Open(mock.Anything).Return(nil)
+		dq.EXPECT().Inventory(mock.Anything).Return(fixtureDevice(), nil)
+		dq.EXPECT().Close(mock.Anything).Return(nil)
+
+		components, err := Outofband(ctx, dq)
+		require.Nil(t, err)
+
+		want := []*Component{
+			{Slug: "bios", Vendor: "dell", Model: "r6515", Serial: "bios-serial", Firmware: "2.6.6"},
+			{Slug: "bmc", Vendor: "dell", Model: "iDRAC9", Serial: "bmc-serial", Firmware: "6.10.30.00"},
+			// NewDevice initializes the mainboard
+			{Slug: "mainboard", Vendor: "dell", Model: "r6515", Serial: "0"},
+		}
+
+		assert.Equal(t, want, components)
+	})
+
+	t.Run("open error", func(t *testing.T) {
+		dq := device.NewMockOutofbandQueryor(t)
+		dq.EXPECT().Open(mock.Anything).Return(errors.New("401: unauthorized"))
+
+		_, err := Outofband(ctx, dq)
+		assert.ErrorIs(t, err, ErrInventory)
+	})
+}
+
+func TestInband(t *testing.T) {
+	dq := device.NewMockInbandQueryor(t)
+	dq.EXPECT().Inventory(mock.Anything).Return(fixtureDevice(), nil)
+
+	components, err := Inband(context.Background(), dq)
+	require.Nil(t, err)
+	assert.Len(t, components, 3)
+}
+
+func TestWrite(t *testing.T) {
+	components := []*Component{
+		{Slug: "bios", Vendor: "dell", Model: "r6515", Serial: "bios-serial", Firmware: "2.6.6"},
+	}
+
+	t.Run("table", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		require.Nil(t, Write(buf, FormatTable, components))
+
+		want := "SLUG  VENDOR  MODEL  SERIAL       FIRMWARE\n" +
+			"bios  dell    r6515  bios-serial  2.6.6\n"
+		assert.Equal(t, want, buf.String())
+	})
+
+	t.Run("json", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		require.Nil(t, Write(buf, FormatJSON, components))
+
+		got := []*Component{}
+		require.Nil(t, json.Unmarshal(buf.Bytes(), &got))
+		assert.Equal(t, components, got)
+	})
+
+	t.Run("yaml", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		require.Nil(t, Write(buf, FormatYAML, components))
+
+		got := []*Component{}
+		require.Nil(t, yaml.Unmarshal(buf.Bytes(), &got))
+		assert.Equal(t, components, got)
+	})
+
+	t.Run("unsupported format", func(t *testing.T) {
+		assert.ErrorIs(t, Write(&bytes.Buffer{}, "xml", components), ErrFormat)
+	})
+}
@@ -0,0 +1,251 @@
+// Package logging configures the logrus loggers used across flasher,
+// and bridges them to the logr interface for dependencies that expect a logr.Logger.
+package logging
+
+import (
+	"strings"
+	"sync"
+
+	runtime "github.com/banzaicloud/logrus-runtime-formatter"
+	"github.com/bombsimon/logrusr/v4"
+	"github.com/go-logr/logr"
+	"github.com/metal-toolbox/flasher/internal/redact"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// FormatJSON, FormatText are the supported log formats.
+	FormatJSON = "json"
+	FormatText = "text"
+
+	// Packages which can be configured with a log level different from the app log level.
+	//
+	// PkgWorker is the logger for each task run by the worker.
+	PkgWorker = "worker"
+	// PkgCtrl is the logger for the condition controller library.
+	PkgCtrl = "ctrl"
+	// PkgBMCLib is the logger for the BMC library.
+	PkgBMCLib = "bmclib"
+	// PkgOtel is the logger for OpenTelemetry.
+	PkgOtel = "otel"
+)
+
+var (
+	ErrParams = errors.New("invalid logging parameters")
+
+	packages = []string{PkgWorker, PkgCtrl, PkgBMCLib, PkgOtel}
+
+	// root is the app logger the package loggers derive their formatter, output from,
+	// this is set by Configure.
+	root     *logrus.Logger
+	levels   map[string]logrus.Level
+	configMu sync.RWMutex
+)
+
+// Params defines the logging configuration.
+type Params struct {
+	// Level is the app log level - one of trace, debug, info, warn, error, defaults to info.
+	Level string
+
+	// Format is the log format - one of json, text, defaults to json.
+	Format string
+
+	// Packages overrides the app log level for the package loggers, keyed by package name.
+	Packages map[string]string
+}
+
+// ParseLevel returns the logrus level for the given level name, an empty level is the info level.
+func ParseLevel(level string) (logrus.Level, error) {
+	if level == "" {
+		return logrus.InfoLevel, nil
+	}
+
+	l, err := logrus.ParseLevel(level)
+	if err != nil {
+		return l, errors.Wrap(ErrParams, err.Error())
+	}
+
+	return l, nil
+}
+
+// Configure applies the logging parameters to the app logger,
+// the package loggers returned by Logger, Logr are configured with the same format, output
+// and the package log level if one is set.
+func Configure(logger *logrus.Logger, p Params) error {
+	level, err := ParseLevel(p.Level)
+	if err != nil {
+		return err
+	}
+
+	formatter, err := newFormatter(p.Format)
+	if err != nil {
+		return err
+	}
+
+	pkgLevels := make(map[string]logrus.Level, len(p.Packages))
+
+	for pkg, pkgLevel := range p.Packages {
+		if !knownPackage(pkg) {
+			return errors.Wrap(
+				ErrParams,
+				"unknown package: "+pkg+", expected one of: "+strings.Join(packages, ", "),
+			)
+		}
+
+		pkgLevels[pkg], err = ParseLevel(pkgLevel)
+		if err != nil {
+			return errors.Wrap(err, "package: "+pkg)
+		}
+	}
+
+	logger.SetLevel(level)
+	logger.SetFormatter(formatter)
+
+	// redact credentials, sensitive values from log entries
+	redact.AddHook(logger)
+
+	configMu.Lock()
+	defer configMu.Unlock()
+
+	root = logger
+	levels = pkgLevels
+
+	return nil
+}
+
+// Level returns the log level for the package, this is the app log level unless the package level is set.
+func Level(pkg string) logrus.Level {
+	configMu.RLock()
+	defer configMu.RUnlock()
+
+	if level, exists := levels[pkg]; exists {
+		return level
+	}
+
+	if root != nil {
+		return root.GetLevel()
+	}
+
+	return logrus.InfoLevel
+}
+
+// Logger returns a new logger for the package, with the app logger format, output and the package log level.
+func Logger(pkg string) *logrus.Logger {
+	logger := logrus.New()
+
+	configMu.RLock()
+	if root != nil {
+		logger.SetFormatter(root.Formatter)
+		logger.SetOutput(root.Out)
+	}
+	configMu.RUnlock()
+
+	logger.SetLevel(Level(pkg))
+	redact.AddHook(logger)
+
+	return logger
+}
+
+// Logr returns a logr.Logger for the package, bridged to a logrus logger returned by Logger.
+func Logr(pkg string) logr.Logger {
+	return newLogr(Logger(pkg))
+}
+
+// LogrFrom returns a logr.Logger for the package derived from the given logger entry,
+// the package logger shares the entry logger format, output and hooks - the task log capture hook for example,
+// includes the entry fields and is set to the package log level.
+func LogrFrom(entry *logrus.Entry, pkg string) logr.Logger {
+	if entry == nil {
+		return Logr(pkg)
+	}
+
+	logger := logrus.New()
+	logger.SetFormatter(entry.Logger.Formatter)
+	logger.SetOutput(entry.Logger.Out)
+	logger.SetLevel(Level(pkg))
+
+	// the hooks are added in the same order, so the redaction hook fires before the task log hook
+	for _, hook := range uniqueHooks(entry.Logger.Hooks) {
+		logger.AddHook(hook)
+	}
+
+	redact.AddHook(logger)
+
+	values := make([]any, 0, len(entry.Data)*2)
+	for k, v := range entry.Data {
+		values = append(values, k, v)
+	}
+
+	return newLogr(logger).WithValues(values...)
+}
+
+// uniqueHooks returns the hooks in the order they were added, each hook is listed once.
+func uniqueHooks(levelHooks logrus.LevelHooks) []logrus.Hook {
+	seen := map[logrus.Hook]bool{}
+	hooks := []logrus.Hook{}
+
+	for _, level := range logrus.AllLevels {
+		for _, hook := range levelHooks[level] {
+			if seen[hook] {
+				continue
+			}
+
+			seen[hook] = true
+			hooks = append(hooks, hook)
+		}
+	}
+
+	return hooks
+}
+
+func newLogr(logger *logrus.Logger) logr.Logger {
+	// logr verbosity levels are mapped to logrus levels below the logrus level,
+	// V(1) is logged at the debug level and V(3) at the trace level, when the logrus level is
+	// raised above the trace level, so the levels are raised here to enable the logr verbose logs.
+	// https://github.com/bombsimon/logrusr/blob/master/logrusr.go#L64
+	switch logger.GetLevel() {
+	case logrus.TraceLevel:
+		logger.Level = 7
+	case logrus.DebugLevel:
+		logger.Level = 5
+	}
+
+	// hooks are registered for the logrus levels, the trace level hooks - redaction, the task log capture,
+	// are registered for the raised levels so these fire for the logr verbose logs.
+	for level := logrus.TraceLevel + 1; level <= logger.Level; level++ {
+		logger.Hooks[level] = append([]logrus.Hook(nil), logger.Hooks[logrus.TraceLevel]...)
+	}
+
+	return logrusr.New(logger)
+}
+
+func newFormatter(format string) (logrus.Formatter, error) {
+	var child logrus.Formatter
+
+	switch format {
+	case "", FormatJSON:
+		child = &logrus.JSONFormatter{}
+	case FormatText:
+		child = &logrus.TextFormatter{}
+	default:
+		return nil, errors.Wrap(ErrParams, "unsupported log format: "+format)
+	}
+
+	return &runtime.Formatter{
+		ChildFormatter: child,
+		File:           true,
+		Line:           true,
+		BaseNameOnly:   true,
+	}, nil
+}
+
+func knownPackage(pkg string) bool {
+	for _, known := range packages {
+		if pkg == known {
+			return true
+		}
+	}
+
+	return false
+}
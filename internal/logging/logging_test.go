@@ -0,0 +1,109 @@
+package logging
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigure(t *testing.T) {
+	tests := []struct {
+		name      string
+		params    Params
+		wantLevel logrus.Level
+		wantErr   error
+	}{
+		{"defaults", Params{}, logrus.InfoLevel, nil},
+		{"trace level, text format", Params{Level: "trace", Format: FormatText}, logrus.TraceLevel, nil},
+		{"invalid level", Params{Level: "verbose"}, 0, ErrParams},
+		{"invalid format", Params{Format: "yaml"}, 0, ErrParams},
+		{"unknown package", Params{Packages: map[string]string{"fleetdb": "debug"}}, 0, ErrParams},
+		{"invalid package level", Params{Packages: map[string]string{PkgCtrl: "verbose"}}, 0, ErrParams},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			logger := logrus.New()
+
+			err := Configure(logger, tc.params)
+			if tc.wantErr != nil {
+				assert.ErrorIs(t, err, tc.wantErr)
+				return
+			}
+
+			require.Nil(t, err)
+			assert.Equal(t, tc.wantLevel, logger.GetLevel())
+		})
+	}
+}
+
+func TestPackageLoggers(t *testing.T) {
+	out := &bytes.Buffer{}
+
+	logger := logrus.New()
+	logger.SetOutput(out)
+
+	require.Nil(t, Configure(logger, Params{
+		Level:    "info",
+		Format:   FormatJSON,
+		Packages: map[string]string{PkgBMCLib: "trace", PkgCtrl: "warn"},
+	}))
+
+	t.Cleanup(func() {
+		require.Nil(t, Configure(logrus.New(), Params{}))
+	})
+
+	assert.Equal(t, logrus.InfoLevel, Level(PkgWorker))
+	assert.Equal(t, logrus.TraceLevel, Level(PkgBMCLib))
+
+	// the package loggers write to the app logger output, in the app logger format
+	ctrlLogger := Logger(PkgCtrl)
+	ctrlLogger.Info("not logged at the warn level")
+	ctrlLogger.Warn("consumer disconnected")
+
+	assert.NotContains(t, out.String(), "not logged")
+	assert.Contains(t, out.String(), `"msg":"consumer disconnected"`)
+
+	// the logr verbose logs are enabled for the trace level
+	out.Reset()
+	Logr(PkgBMCLib).V(3).Info("redfish request", "method", "GET")
+	assert.Contains(t, out.String(), "redfish request")
+
+	out.Reset()
+	Logr(PkgOtel).V(1).Info("exporter retry")
+	assert.Empty(t, out.String())
+}
+
+func TestLogrFrom(t *testing.T) {
+	out := &bytes.Buffer{}
+
+	require.Nil(t, Configure(logrus.New(), Params{Packages: map[string]string{PkgBMCLib: "trace"}}))
+
+	t.Cleanup(func() {
+		require.Nil(t, Configure(logrus.New(), Params{}))
+	})
+
+	// the task logger with a hook capturing its entries
+	taskLogger := logrus.New()
+	taskLogger.SetOutput(out)
+	taskLogger.SetLevel(logrus.InfoLevel)
+
+	hook := test.NewLocal(taskLogger)
+
+	entry := taskLogger.WithField("conditionID", "fa125199")
+
+	// the package logger shares the task logger hooks, fields and is set to the package level
+	LogrFrom(entry, PkgBMCLib).V(3).Info("redfish request", "method", "GET")
+
+	require.Len(t, hook.AllEntries(), 1)
+	assert.Equal(t, "redfish request", hook.LastEntry().Message)
+	assert.Equal(t, "fa125199", hook.LastEntry().Data["conditionID"])
+	assert.Contains(t, out.String(), "redfish request")
+
+	// the task logger level is not changed
+	assert.Equal(t, logrus.InfoLevel, taskLogger.GetLevel())
+}
@@ -30,6 +30,14 @@ var (
 	UploadBytes            *prometheus.CounterVec
 	UploadRunTimeSummary   *prometheus.SummaryVec
 
+	TasksInFlight   prometheus.Gauge
+	ActionsInFlight prometheus.Gauge
+
+	TaskFailuresCounter *prometheus.CounterVec
+
+	StatusPublishRunTimeSummary *prometheus.SummaryVec
+	StatusPublishErrors         *prometheus.CounterVec
+
 	StoreQueryErrorCount *prometheus.CounterVec
 
 	NATSErrors *prometheus.CounterVec
@@ -76,6 +84,14 @@ func init() {
 		[]string{"component", "vendor"},
 	)
 
+	DownloadRunTimeSummary = promauto.NewSummaryVec(
+		prometheus.SummaryOpts{
+			Name: "flasher_download_duration_seconds",
+			Help: "A summary metric to measure the total time spent in downloading and verifying firmware",
+		},
+		[]string{"component", "vendor", "state"},
+	)
+
 	UploadBytes = promauto.NewCounterVec(
 		prometheus.CounterOpts{
 			Name: "flasher_upload_bytes",
@@ -84,6 +100,52 @@ func init() {
 		[]string{"component", "vendor"},
 	)
 
+	UploadRunTimeSummary = promauto.NewSummaryVec(
+		prometheus.SummaryOpts{
+			Name: "flasher_upload_duration_seconds",
+			Help: "A summary metric to measure the total time spent in uploading firmware to the device",
+		},
+		[]string{"component", "vendor", "state"},
+	)
+
+	TasksInFlight = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "flasher_tasks_in_flight",
+			Help: "A gauge metric of the count of tasks being run",
+		},
+	)
+
+	ActionsInFlight = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "flasher_install_actions_in_flight",
+			Help: "A gauge metric of the count of install actions being run",
+		},
+	)
+
+	TaskFailuresCounter = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "flasher_task_failures",
+			Help: "A counter metric of failed tasks by the failure code, device vendor, model and the component being installed",
+		},
+		[]string{"code", "vendor", "model", "component"},
+	)
+
+	StatusPublishRunTimeSummary = promauto.NewSummaryVec(
+		prometheus.SummaryOpts{
+			Name: "flasher_status_publish_duration_seconds",
+			Help: "A summary metric to measure the time spent in each task status publish attempt",
+		},
+		[]string{"state"},
+	)
+
+	StatusPublishErrors = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "flasher_status_publish_errors",
+			Help: "A counter metric of failed task status publish attempts, result is retried or dropped",
+		},
+		[]string{"result"},
+	)
+
 	StoreQueryErrorCount = promauto.NewCounterVec(
 		prometheus.CounterOpts{
 			Name: "flasher_store_query_error_count",
@@ -132,3 +194,51 @@ func RegisterSpanEvent(span trace.Span, condition *rctypes.Condition, workerID,
 func NATSError(op string) {
 	NATSErrors.WithLabelValues(op).Inc()
 }
+
+// ObserveDownload records the time spent downloading and verifying the firmware file.
+func ObserveDownload(startTS time.Time, component, vendor string, err error) {
+	DownloadRunTimeSummary.With(
+		prometheus.Labels{
+			"component": component,
+			"vendor":    vendor,
+			"state":     stateLabel(err),
+		},
+	).Observe(time.Since(startTS).Seconds())
+}
+
+// ObserveUpload records the time spent uploading the firmware file to the device.
+func ObserveUpload(startTS time.Time, component, vendor string, err error) {
+	UploadRunTimeSummary.With(
+		prometheus.Labels{
+			"component": component,
+			"vendor":    vendor,
+			"state":     stateLabel(err),
+		},
+	).Observe(time.Since(startTS).Seconds())
+}
+
+// ObserveStatusPublish records the time spent in a task status publish attempt.
+func ObserveStatusPublish(startTS time.Time, err error) {
+	StatusPublishRunTimeSummary.With(
+		prometheus.Labels{"state": stateLabel(err)},
+	).Observe(time.Since(startTS).Seconds())
+}
+
+// StatusPublishError counts a failed task status publish attempt,
+// dropped is true when the update is not retried.
+func StatusPublishError(dropped bool) {
+	result := "retried"
+	if dropped {
+		result = "dropped"
+	}
+
+	StatusPublishErrors.WithLabelValues(result).Inc()
+}
+
+func stateLabel(err error) string {
+	if err != nil {
+		return string(rctypes.Failed)
+	}
+
+	return string(rctypes.Succeeded)
+}
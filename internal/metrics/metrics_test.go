@@ -0,0 +1,49 @@
+package metrics
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestObserveDownloadUpload(t *testing.T) {
+	sampleCount := func(t *testing.T, observer prometheus.Observer) uint64 {
+		t.Helper()
+
+		m := &dto.Metric{}
+		require.Nil(t, observer.(prometheus.Metric).Write(m))
+
+		return m.GetSummary().GetSampleCount()
+	}
+
+	tests := []struct {
+		name    string
+		observe func(startTS time.Time, component, vendor string, err error)
+		summary *prometheus.SummaryVec
+	}{
+		{"download", ObserveDownload, DownloadRunTimeSummary},
+		{"upload", ObserveUpload, UploadRunTimeSummary},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.summary.Reset()
+
+			startTS := time.Now()
+			tc.observe(startTS, "bios", "dell", nil)
+			tc.observe(startTS, "bios", "dell", nil)
+			tc.observe(startTS, "bmc", "dell", errors.New("checksum mismatch"))
+
+			// a series for each component, state
+			assert.Equal(t, 2, testutil.CollectAndCount(tc.summary))
+			assert.Equal(t, uint64(2), sampleCount(t, tc.summary.WithLabelValues("bios", "dell", "succeeded")))
+			assert.Equal(t, uint64(1), sampleCount(t, tc.summary.WithLabelValues("bmc", "dell", "failed")))
+		})
+	}
+}
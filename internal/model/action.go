@@ -26,6 +26,13 @@ type Action struct {
 	// or an install was initiated on the BMC .
 	BMCTaskID string `json:"bmc_task_id,omitempty"`
 
+	// BMCTaskPercent is the BMC job percent complete, when included in the BMC task status.
+	BMCTaskPercent int `json:"bmc_task_percent,omitempty"`
+
+	// DownloadedBytes, UploadedBytes are the size of the firmware file downloaded, and uploaded to the BMC.
+	DownloadedBytes int64 `json:"downloaded_bytes,omitempty"`
+	UploadedBytes   int64 `json:"uploaded_bytes,omitempty"`
+
 	// Set to the component identified as the target of the firmware install
 	Component *rtypes.Component `json:"component"`
 
@@ -69,6 +76,9 @@ type Action struct {
 	// HostPowerOffInitiated indicates a power off was initated on the host.
 	HostPowerOffInitiated bool `json:"host_power_off_initiated"`
 
+	// HostPowerOnInitiated indicates a power on was initiated on the host.
+	HostPowerOnInitiated bool `json:"host_power_on_initiated"`
+
 	// HostPowerOffPreInstall is set when the firmware install provider indicates
 	// the host must be powered off before proceeding with the install step.
 	HostPowerOffPreInstall bool `json:"host_power_off_pre_install"`
@@ -84,6 +94,9 @@ type Action struct {
 
 	// Steps identify the smallest unit of work executed by an action
 	Steps Steps `json:"steps"`
+
+	// Timing records the action start, completion and attempts.
+	Timing
 }
 
 func (a *Action) SetID(taskID, componentSlug string, idx int) {
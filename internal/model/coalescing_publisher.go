@@ -0,0 +1,345 @@
+package model
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/metal-toolbox/flasher/internal/metrics"
+	rctypes "github.com/metal-toolbox/rivets/v2/condition"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	ErrPublishDropped = errors.New("task update dropped after publish attempts")
+)
+
+// Flusher is implemented by publishers that defer publishing task updates.
+type Flusher interface {
+	// Flush publishes the task and waits until it is published.
+	Flush(ctx context.Context, task *Task) error
+}
+
+// PublishFlush publishes the task, waiting until it is published if the publisher defers publishing task updates.
+func PublishFlush(ctx context.Context, p Publisher, task *Task) error {
+	if f, ok := p.(Flusher); ok {
+		return f.Flush(ctx, task)
+	}
+
+	return p.Publish(ctx, task)
+}
+
+// CoalescingPublisherParams defines how task updates are coalesced and retried.
+type CoalescingPublisherParams struct {
+	// Interval is the minimum time between publishing task updates,
+	// updates received within the interval are coalesced into the latest update.
+	Interval time.Duration
+
+	// RetryMin, RetryMax are the initial and the max delay between publish attempts,
+	// the delay is doubled after each failed attempt.
+	RetryMin time.Duration
+	RetryMax time.Duration
+
+	// MaxAttempts is the number of attempts to publish a task update before the update is dropped,
+	// final task states are retried until the FlushTimeout.
+	MaxAttempts int
+
+	// FlushTimeout is the time budget to publish the final task state.
+	FlushTimeout time.Duration
+}
+
+// DefaultCoalescingPublisherParams returns the default parameters,
+// task updates are published at most every 2s and retried 5 times,
+// the final task state is retried for upto 2 minutes.
+func DefaultCoalescingPublisherParams() CoalescingPublisherParams {
+	return CoalescingPublisherParams{
+		Interval:     2 * time.Second,
+		RetryMin:     500 * time.Millisecond,
+		RetryMax:     10 * time.Second,
+		MaxAttempts:  5,
+		FlushTimeout: 2 * time.Minute,
+	}
+}
+
+// CoalescingPublisher decorates a Publisher to coalesce rapid task updates and retry failed publishes.
+//
+// Task updates are published in order by a single goroutine, an update waiting to be published
+// is replaced by a newer update. Publishing a final task state blocks until it is published,
+// or the FlushTimeout is exceeded.
+type CoalescingPublisher struct {
+	next   Publisher
+	logger *logrus.Entry
+	params CoalescingPublisherParams
+
+	mu       sync.Mutex
+	pending  *publishUpdate
+	lastSent time.Time
+	closed   bool
+
+	wake    chan struct{}
+	closing chan struct{}
+	stopped chan struct{}
+}
+
+// publishUpdate is a task update to be published, along with the callers waiting on it being published.
+type publishUpdate struct {
+	ctx  context.Context
+	task *Task
+	// final is set for the final task state and flushed updates,
+	// these are published without waiting for the interval and retried until the FlushTimeout.
+	final bool
+	// flushBy is the deadline to publish a final update, set when the first final update is queued
+	// and retained when the update is superseded by newer updates.
+	flushBy time.Time
+	waiters []chan error
+}
+
+// supersede returns the newer update carrying over the waiters and the flush deadline of the update it replaces.
+func (u *publishUpdate) supersede(newer *publishUpdate) *publishUpdate {
+	newer.waiters = append(u.waiters, newer.waiters...)
+	newer.final = newer.final || u.final
+
+	if !u.flushBy.IsZero() && (newer.flushBy.IsZero() || u.flushBy.Before(newer.flushBy)) {
+		newer.flushBy = u.flushBy
+	}
+
+	return newer
+}
+
+// NewCoalescingPublisher returns a CoalescingPublisher publishing task updates with the given publisher,
+// Close is to be called once the task is complete.
+func NewCoalescingPublisher(logger *logrus.Entry, next Publisher, params CoalescingPublisherParams) *CoalescingPublisher {
+	p := &CoalescingPublisher{
+		next:    next,
+		logger:  logger,
+		params:  params,
+		wake:    make(chan struct{}, 1),
+		closing: make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+
+	go p.run()
+
+	return p
+}
+
+// Publish queues a snapshot of the task to be published, for a final task state
+// this blocks until the task is published or the context is canceled and returns the publish error if any.
+func (p *CoalescingPublisher) Publish(ctx context.Context, task *Task) error {
+	return p.enqueue(ctx, task, rctypes.StateIsComplete(task.State))
+}
+
+// Flush queues a snapshot of the task to be published and blocks until the task is published or the context is canceled,
+// the update is retried until the FlushTimeout as with a final task state.
+func (p *CoalescingPublisher) Flush(ctx context.Context, task *Task) error {
+	return p.enqueue(ctx, task, true)
+}
+
+func (p *CoalescingPublisher) enqueue(ctx context.Context, task *Task, final bool) error {
+	snapshot, err := snapshotTask(task)
+	if err != nil {
+		err = errors.Wrap(ErrPublishTask, err.Error())
+		p.logger.WithError(err).Warn("Task publish error")
+
+		return err
+	}
+
+	update := &publishUpdate{ctx: context.WithoutCancel(ctx), task: snapshot, final: final}
+
+	var waiter chan error
+	if final {
+		waiter = make(chan error, 1)
+		update.waiters = []chan error{waiter}
+		update.flushBy = time.Now().Add(p.params.FlushTimeout)
+	}
+
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+
+		return p.next.Publish(ctx, snapshot)
+	}
+
+	if p.pending != nil {
+		// the newer update supersedes the update waiting to be published
+		update = p.pending.supersede(update)
+	}
+
+	p.pending = update
+	p.mu.Unlock()
+
+	p.notify()
+
+	if waiter == nil {
+		return nil
+	}
+
+	// the update remains queued when the caller gives up waiting on it
+	select {
+	case err := <-waiter:
+		return err
+	case <-ctx.Done():
+		return errors.Wrap(ErrPublishStatus, ctx.Err().Error())
+	}
+}
+
+// Close publishes any pending task update and stops the publisher.
+func (p *CoalescingPublisher) Close() {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return
+	}
+
+	p.closed = true
+	p.mu.Unlock()
+
+	close(p.closing)
+	<-p.stopped
+}
+
+func (p *CoalescingPublisher) notify() {
+	select {
+	case p.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (p *CoalescingPublisher) run() {
+	defer close(p.stopped)
+
+	for {
+		select {
+		case <-p.wake:
+			p.coalesce()
+		case <-p.closing:
+		}
+
+		update := p.take()
+		if update != nil {
+			p.publish(update)
+			continue
+		}
+
+		select {
+		case <-p.closing:
+			return
+		default:
+		}
+	}
+}
+
+// coalesce waits out the remaining interval since the last publish, collecting newer updates,
+// the wait ends early for a final task state or when the publisher is closed.
+func (p *CoalescingPublisher) coalesce() {
+	p.mu.Lock()
+	wait := p.params.Interval - time.Since(p.lastSent)
+	p.mu.Unlock()
+
+	if wait <= 0 {
+		return
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	for {
+		if p.pendingFinal() {
+			return
+		}
+
+		select {
+		case <-timer.C:
+			return
+		case <-p.closing:
+			return
+		case <-p.wake:
+		}
+	}
+}
+
+func (p *CoalescingPublisher) pendingFinal() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.pending != nil && p.pending.final
+}
+
+func (p *CoalescingPublisher) take() *publishUpdate {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	update := p.pending
+	p.pending = nil
+
+	return update
+}
+
+// publish attempts to publish the update with backoff, switching to a newer update
+// if one is received in between attempts so updates are never published out of order.
+func (p *CoalescingPublisher) publish(update *publishUpdate) {
+	var (
+		err   error
+		delay = p.params.RetryMin
+	)
+
+	for attempt := 1; ; attempt++ {
+		startTS := time.Now()
+		err = p.next.Publish(update.ctx, update.task)
+		metrics.ObserveStatusPublish(startTS, err)
+
+		if err == nil {
+			break
+		}
+
+		dropped := (!update.final && attempt >= p.params.MaxAttempts) ||
+			(update.final && time.Now().Add(delay).After(update.flushBy))
+
+		metrics.StatusPublishError(dropped)
+
+		if dropped {
+			p.logger.WithError(err).WithField("attempts", attempt).Error("Task update dropped")
+			err = errors.Wrap(ErrPublishDropped, err.Error())
+
+			break
+		}
+
+		// the retries continue when the publisher is being closed, so the final task state is flushed
+		time.Sleep(delay)
+		delay = min(delay*2, p.params.RetryMax)
+
+		if newer := p.take(); newer != nil {
+			update = update.supersede(newer)
+			attempt = 0
+		}
+	}
+
+	p.mu.Lock()
+	p.lastSent = time.Now()
+	p.mu.Unlock()
+
+	for _, waiter := range update.waiters {
+		waiter <- err
+	}
+}
+
+// snapshotTask returns a copy of the task, since the task is published after the caller has moved on.
+func snapshotTask(task *Task) (*Task, error) {
+	generic, err := CopyAsGenericTask(task)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot, err := CopyAsFwInstallTask(generic)
+	if err != nil {
+		return nil, err
+	}
+
+	// the status messages are copied since the slice is shared with the task
+	snapshot.Status = rctypes.StatusRecord{
+		StatusMsgs: append([]rctypes.StatusMsg(nil), task.Status.StatusMsgs...),
+	}
+
+	return snapshot, nil
+}
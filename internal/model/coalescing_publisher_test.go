@@ -0,0 +1,239 @@
+package model
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/metal-toolbox/flasher/internal/metrics"
+	rctypes "github.com/metal-toolbox/rivets/v2/condition"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakePublisher records the published task status messages, failing the first 'failures' attempts.
+type fakePublisher struct {
+	mu        sync.Mutex
+	failures  int
+	attempts  int
+	published []string
+}
+
+func (f *fakePublisher) Publish(_ context.Context, task *Task) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.attempts++
+	if f.attempts <= f.failures {
+		return errors.New("nats: timeout")
+	}
+
+	f.published = append(f.published, task.Status.Last())
+
+	return nil
+}
+
+func (f *fakePublisher) result() (attempts int, published []string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.attempts, append([]string(nil), f.published...)
+}
+
+func testCoalescingParams() CoalescingPublisherParams {
+	return CoalescingPublisherParams{
+		Interval:     50 * time.Millisecond,
+		RetryMin:     time.Millisecond,
+		RetryMax:     5 * time.Millisecond,
+		MaxAttempts:  3,
+		FlushTimeout: time.Second,
+	}
+}
+
+func newTestTask(t *testing.T) *Task {
+	t.Helper()
+
+	task, err := NewTask(uuid.New(), rctypes.FirmwareInstall, &rctypes.FirmwareInstallTaskParameters{
+		Firmwares: []rctypes.Firmware{{Component: "bios", Version: "2.19.6"}},
+	})
+	require.Nil(t, err)
+
+	return &task
+}
+
+func TestCoalescingPublisher(t *testing.T) {
+	tests := []struct {
+		name          string
+		failures      int
+		updates       []string
+		wantPublished []string
+	}{
+		{
+			"updates coalesced, final state flushed",
+			0,
+			[]string{"update-1", "update-2", "update-3", "update-4"},
+			// the first update is published right away, the others are coalesced into the final state
+			[]string{"update-1", "task completed"},
+		},
+		{
+			"failed publish retried",
+			2,
+			[]string{"update-1"},
+			[]string{"task completed"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			next := &fakePublisher{failures: tc.failures}
+			publisher := NewCoalescingPublisher(logrus.NewEntry(logrus.New()), next, testCoalescingParams())
+			defer publisher.Close()
+
+			task := newTestTask(t)
+			for _, update := range tc.updates {
+				task.AppendStatus(update)
+				require.Nil(t, publisher.Publish(context.Background(), task))
+
+				// let the first update be picked up before the rest are queued
+				time.Sleep(5 * time.Millisecond)
+			}
+
+			// the final state publish blocks until published
+			task.SetState(StateSucceeded)
+			task.AppendStatus("task completed")
+			assert.Nil(t, publisher.Publish(context.Background(), task))
+
+			_, published := next.result()
+			if tc.failures == 0 {
+				assert.Equal(t, tc.wantPublished, published)
+				return
+			}
+
+			// the update being retried is superseded by the final state
+			assert.Equal(t, tc.wantPublished, published[len(published)-1:])
+		})
+	}
+}
+
+func TestCoalescingPublisherDropped(t *testing.T) {
+	metrics.StatusPublishErrors.Reset()
+
+	next := &fakePublisher{failures: 100}
+	publisher := NewCoalescingPublisher(logrus.NewEntry(logrus.New()), next, testCoalescingParams())
+
+	task := newTestTask(t)
+	task.AppendStatus("update-1")
+	require.Nil(t, publisher.Publish(context.Background(), task))
+
+	// close publishes the pending update
+	publisher.Close()
+
+	attempts, published := next.result()
+	assert.Equal(t, 3, attempts)
+	assert.Empty(t, published)
+	assert.Equal(t, float64(2), testutil.ToFloat64(metrics.StatusPublishErrors.WithLabelValues("retried")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(metrics.StatusPublishErrors.WithLabelValues("dropped")))
+
+	// the final state is retried until the flush timeout
+	params := testCoalescingParams()
+	params.FlushTimeout = 20 * time.Millisecond
+
+	publisher = NewCoalescingPublisher(logrus.NewEntry(logrus.New()), next, params)
+	defer publisher.Close()
+
+	task.SetState(StateFailed)
+	assert.ErrorIs(t, publisher.Publish(context.Background(), task), ErrPublishDropped)
+}
+
+func TestCoalescingPublisherFlush(t *testing.T) {
+	next := &fakePublisher{failures: 1}
+	publisher := NewCoalescingPublisher(logrus.NewEntry(logrus.New()), next, testCoalescingParams())
+	defer publisher.Close()
+
+	task := newTestTask(t)
+	task.AppendStatus("server powercycle flag set, waiting for powercycle")
+	require.Nil(t, PublishFlush(context.Background(), publisher, task))
+
+	// the flushed task is published before the call returns
+	_, published := next.result()
+	assert.Equal(t, []string{"server powercycle flag set, waiting for powercycle"}, published)
+}
+
+func TestCoalescingPublisherFlushDeadline(t *testing.T) {
+	next := &fakePublisher{failures: 1000}
+
+	params := testCoalescingParams()
+	params.FlushTimeout = 50 * time.Millisecond
+
+	publisher := NewCoalescingPublisher(logrus.NewEntry(logrus.New()), next, params)
+	defer publisher.Close()
+
+	task := newTestTask(t)
+	task.AppendStatus("flushed update")
+
+	startTS := time.Now()
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- PublishFlush(context.Background(), publisher, task)
+	}()
+
+	// newer updates supersede the flushed update while it is being retried,
+	// the flush deadline is retained from when the flushed update was queued.
+	ticker := time.NewTicker(5 * time.Millisecond)
+	defer ticker.Stop()
+
+	timeout := time.After(2 * time.Second)
+
+	for {
+		select {
+		case err := <-errCh:
+			assert.ErrorIs(t, err, ErrPublishDropped)
+			assert.Less(t, time.Since(startTS), time.Second)
+
+			return
+		case <-ticker.C:
+			update := newTestTask(t)
+			update.ID = task.ID
+			update.AppendStatus("newer update")
+			require.Nil(t, publisher.Publish(context.Background(), update))
+		case <-timeout:
+			t.Fatal("flush blocked past the flush timeout")
+		}
+	}
+}
+
+func TestCoalescingPublisherFlushCanceled(t *testing.T) {
+	next := &fakePublisher{failures: 1000}
+
+	params := testCoalescingParams()
+	params.FlushTimeout = time.Minute
+
+	publisher := NewCoalescingPublisher(logrus.NewEntry(logrus.New()), next, params)
+
+	task := newTestTask(t)
+	task.SetState(StateFailed)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	// the caller stops waiting once its context is canceled
+	startTS := time.Now()
+	assert.ErrorIs(t, publisher.Publish(ctx, task), ErrPublishStatus)
+	assert.Less(t, time.Since(startTS), time.Second)
+
+	// the update is published once the publisher recovers
+	next.mu.Lock()
+	next.failures = 0
+	next.mu.Unlock()
+
+	publisher.Close()
+
+	_, published := next.result()
+	assert.NotEmpty(t, published)
+}
@@ -0,0 +1,142 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrTaskDeadlineExceeded = errors.New("task deadline exceeded")
+	ErrTaskTimeoutParam     = errors.New("invalid task timeout parameter")
+
+	// defaultTaskTimeout is the task timeout when the task parameters do not include one,
+	// a zero value is no deadline, this can be set with SetDefaultTaskTimeout.
+	defaultTaskTimeout   time.Duration
+	defaultTaskTimeoutMu sync.RWMutex
+)
+
+func init() {
+	RegisterFailureCode(FailureDeadlineExceeded, ErrTaskDeadlineExceeded)
+}
+
+// SetDefaultTaskTimeout sets the timeout for tasks which do not include a timeout parameter,
+// a zero value disables the default deadline.
+func SetDefaultTaskTimeout(d time.Duration) error {
+	if d < 0 {
+		return errors.Wrap(ErrTaskTimeoutParam, "default task timeout is expected to be a positive value")
+	}
+
+	defaultTaskTimeoutMu.Lock()
+	defer defaultTaskTimeoutMu.Unlock()
+
+	defaultTaskTimeout = d
+
+	return nil
+}
+
+func currentDefaultTaskTimeout() time.Duration {
+	defaultTaskTimeoutMu.RLock()
+	defer defaultTaskTimeoutMu.RUnlock()
+
+	return defaultTaskTimeout
+}
+
+// SetDeadline sets the task deadline from the task timeout parameter, or the default task timeout,
+// the deadline of a resumed task is left as is.
+func (t *Task) SetDeadline(now time.Time) error {
+	if t.Data == nil || t.Data.Deadline != nil {
+		return nil
+	}
+
+	timeout := currentDefaultTaskTimeout()
+
+	if t.Data.Timeout != "" {
+		var err error
+
+		timeout, err = parseTaskTimeout(t.Data.Timeout)
+		if err != nil {
+			return err
+		}
+	}
+
+	if timeout == 0 {
+		return nil
+	}
+
+	deadline := now.Add(timeout)
+	t.Data.Deadline = &deadline
+
+	return nil
+}
+
+// DeadlineExceeded returns an error when the task deadline has passed,
+// the error includes the component, step the task was on - either of which may be empty.
+func (t *Task) DeadlineExceeded(now time.Time, component, step string) error {
+	if t.Data == nil || t.Data.Deadline == nil || now.Before(*t.Data.Deadline) {
+		return nil
+	}
+
+	msg := fmt.Sprintf("deadline: %s", t.Data.Deadline.Format(time.RFC3339))
+	if component != "" {
+		msg += ", component: " + component
+	}
+
+	if step != "" {
+		msg += ", step: " + step
+	}
+
+	return errors.Wrap(ErrTaskDeadlineExceeded, msg)
+}
+
+func parseTaskTimeout(s string) (time.Duration, error) {
+	timeout, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, errors.Wrap(ErrTaskTimeoutParam, err.Error())
+	}
+
+	if timeout < 0 {
+		return 0, errors.Wrap(ErrTaskTimeoutParam, "timeout is expected to be a positive value: "+s)
+	}
+
+	return timeout, nil
+}
+
+// convTaskTimeout returns the optional timeout parameter from the task parameters,
+// the timeout is not part of the firmware install parameters and so is read from the parameters as received.
+func convTaskTimeout(params any) (string, error) {
+	var b []byte
+
+	switch v := params.(type) {
+	case map[string]interface{}:
+		var err error
+		if b, err = json.Marshal(v); err != nil {
+			return "", errors.Wrap(ErrTaskTimeoutParam, err.Error())
+		}
+	case json.RawMessage:
+		b = v
+	default:
+		return "", nil
+	}
+
+	p := struct {
+		Timeout string `json:"timeout"`
+	}{}
+
+	if err := json.Unmarshal(b, &p); err != nil {
+		return "", errors.Wrap(ErrTaskTimeoutParam, err.Error())
+	}
+
+	if p.Timeout == "" {
+		return "", nil
+	}
+
+	if _, err := parseTaskTimeout(p.Timeout); err != nil {
+		return "", err
+	}
+
+	return p.Timeout, nil
+}
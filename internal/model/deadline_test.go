@@ -0,0 +1,125 @@
+package model
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	rctypes "github.com/metal-toolbox/rivets/v2/condition"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCopyAsFwInstallTaskTimeout(t *testing.T) {
+	tests := []struct {
+		name            string
+		params          any
+		expectedTimeout string
+		expectedErr     error
+	}{
+		{
+			name:            "raw params",
+			params:          json.RawMessage(`{"firmwares":[{"component":"bios","version":"2.19.6"}],"timeout":"2h"}`),
+			expectedTimeout: "2h",
+		},
+		{
+			name:            "map params",
+			params:          map[string]interface{}{"firmwares": []interface{}{}, "timeout": "90m"},
+			expectedTimeout: "90m",
+		},
+		{
+			name:   "no timeout",
+			params: json.RawMessage(`{"firmwares":[]}`),
+		},
+		{
+			name:        "invalid timeout",
+			params:      json.RawMessage(`{"firmwares":[],"timeout":"soon"}`),
+			expectedErr: ErrTaskTimeoutParam,
+		},
+		{
+			name:        "negative timeout",
+			params:      json.RawMessage(`{"firmwares":[],"timeout":"-1h"}`),
+			expectedErr: ErrTaskTimeoutParam,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task, err := CopyAsFwInstallTask(&rctypes.Task[any, any]{
+				Kind:       rctypes.FirmwareInstall,
+				Parameters: tt.params,
+				Data:       json.RawMessage(`{}`),
+			})
+
+			if tt.expectedErr != nil {
+				assert.ErrorContains(t, err, tt.expectedErr.Error())
+				return
+			}
+
+			require.Nil(t, err)
+			assert.Equal(t, tt.expectedTimeout, task.Data.Timeout)
+		})
+	}
+}
+
+func TestSetDeadline(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	resumed := now.Add(-time.Hour)
+
+	tests := []struct {
+		name             string
+		data             *TaskData
+		defaultTimeout   time.Duration
+		expectedDeadline *time.Time
+	}{
+		{
+			name: "no timeout",
+			data: &TaskData{},
+		},
+		{
+			name:             "timeout parameter",
+			data:             &TaskData{Timeout: "2h"},
+			defaultTimeout:   time.Hour,
+			expectedDeadline: func() *time.Time { d := now.Add(2 * time.Hour); return &d }(),
+		},
+		{
+			name:             "default timeout",
+			data:             &TaskData{},
+			defaultTimeout:   time.Hour,
+			expectedDeadline: func() *time.Time { d := now.Add(time.Hour); return &d }(),
+		},
+		{
+			name:             "resumed task retains deadline",
+			data:             &TaskData{Timeout: "2h", Deadline: &resumed},
+			expectedDeadline: &resumed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Nil(t, SetDefaultTaskTimeout(tt.defaultTimeout))
+			defer func() { _ = SetDefaultTaskTimeout(0) }()
+
+			task := &Task{Data: tt.data}
+			require.Nil(t, task.SetDeadline(now))
+			assert.Equal(t, tt.expectedDeadline, task.Data.Deadline)
+		})
+	}
+
+	assert.ErrorIs(t, SetDefaultTaskTimeout(-time.Second), ErrTaskTimeoutParam)
+}
+
+func TestDeadlineExceeded(t *testing.T) {
+	deadline := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	task := &Task{Data: &TaskData{Deadline: &deadline}}
+
+	assert.Nil(t, task.DeadlineExceeded(deadline.Add(-time.Second), "bios", "downloadFirmware"))
+
+	err := task.DeadlineExceeded(deadline, "bios", "downloadFirmware")
+	assert.ErrorIs(t, err, ErrTaskDeadlineExceeded)
+	assert.EqualError(t, err, "deadline: 2024-01-01T00:00:00Z, component: bios, step: downloadFirmware: task deadline exceeded")
+	assert.Equal(t, FailureDeadlineExceeded, ClassifyFailure(err))
+
+	// no deadline set
+	assert.Nil(t, (&Task{Data: &TaskData{}}).DeadlineExceeded(deadline, "", ""))
+}
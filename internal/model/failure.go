@@ -0,0 +1,146 @@
+package model
+
+import (
+	"context"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// FailureCode is the machine readable reason a task failed.
+type FailureCode string
+
+const (
+	FailureBMCLogin             FailureCode = "bmc_login"
+	FailureDownload             FailureCode = "download"
+	FailureChecksum             FailureCode = "checksum_mismatch"
+	FailureBMCJob               FailureCode = "bmc_job_failed"
+	FailureVerificationMismatch FailureCode = "verification_mismatch"
+	FailureTimeout              FailureCode = "timeout"
+	FailureStoreQuery           FailureCode = "store_query"
+	FailureDeadlineExceeded     FailureCode = "deadline_exceeded"
+	FailureUnknown              FailureCode = "unknown"
+)
+
+// failureCodeOrder is the order failure codes are matched in,
+// when an error is registered with more than one failure code.
+var failureCodeOrder = []FailureCode{
+	FailureDeadlineExceeded,
+	FailureBMCLogin,
+	FailureChecksum,
+	FailureDownload,
+	FailureBMCJob,
+	FailureVerificationMismatch,
+	FailureTimeout,
+	FailureStoreQuery,
+}
+
+var (
+	failureMu     sync.RWMutex
+	failureErrors = map[FailureCode][]error{}
+)
+
+// RegisterFailureCode classifies errors that match any of the given errors with the failure code.
+//
+// Packages register their sentinel errors on init.
+func RegisterFailureCode(code FailureCode, errs ...error) {
+	failureMu.Lock()
+	defer failureMu.Unlock()
+
+	failureErrors[code] = append(failureErrors[code], errs...)
+}
+
+// Failure is an error along with the failure code it was classified as.
+type Failure struct {
+	Code FailureCode
+	err  error
+}
+
+func (f *Failure) Error() string {
+	return f.err.Error()
+}
+
+func (f *Failure) Unwrap() error {
+	return f.err
+}
+
+// WrapFailure returns err wrapped with the cause message as errors.Wrap(err, cause.Error()) does,
+// the failure code of the cause, which would otherwise be lost, is retained on the returned error.
+func WrapFailure(err, cause error) error {
+	wrapped := errors.Wrap(err, cause.Error())
+
+	code := ClassifyFailure(cause)
+	if code == FailureUnknown {
+		return wrapped
+	}
+
+	return &Failure{Code: code, err: wrapped}
+}
+
+// ClassifyFailure returns the failure code for the error,
+// an empty code is returned for a nil error.
+//
+// The error is classified by the terminal error in its chain - the innermost wrapped error,
+// for a multierror - the errors accumulated over attempts for example, this is the last classified error,
+// so an error that ended the attempts is not masked by the errors seen in earlier attempts.
+func ClassifyFailure(err error) FailureCode {
+	if err == nil {
+		return ""
+	}
+
+	return classifyFailure(err)
+}
+
+func classifyFailure(err error) FailureCode {
+	for err != nil {
+		var errs []error
+
+		switch e := err.(type) {
+		case *Failure:
+			return e.Code
+		case interface{ WrappedErrors() []error }:
+			errs = e.WrappedErrors()
+		case interface{ Unwrap() []error }:
+			errs = e.Unwrap()
+		}
+
+		if errs != nil {
+			for i := len(errs) - 1; i >= 0; i-- {
+				if code := classifyFailure(errs[i]); code != FailureUnknown {
+					return code
+				}
+			}
+
+			return FailureUnknown
+		}
+
+		next := errors.Unwrap(err)
+		if next == nil {
+			return classifyTerminal(err)
+		}
+
+		err = next
+	}
+
+	return FailureUnknown
+}
+
+// classifyTerminal returns the failure code the terminal error in an error chain was registered with.
+func classifyTerminal(err error) FailureCode {
+	failureMu.RLock()
+	defer failureMu.RUnlock()
+
+	for _, code := range failureCodeOrder {
+		for _, target := range failureErrors[code] {
+			if errors.Is(err, target) {
+				return code
+			}
+		}
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return FailureTimeout
+	}
+
+	return FailureUnknown
+}
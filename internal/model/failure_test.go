@@ -0,0 +1,101 @@
+package model
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassifyFailure(t *testing.T) {
+	errLogin := errors.New("login failed")
+	errJob := errors.New("job failed")
+	errStep := errors.New("step failed")
+
+	errPollTimeout := errors.New("poll timeout")
+
+	RegisterFailureCode(FailureBMCLogin, errLogin)
+	RegisterFailureCode(FailureBMCJob, errJob)
+	RegisterFailureCode(FailureTimeout, errPollTimeout)
+
+	tests := []struct {
+		name string
+		err  error
+		want FailureCode
+	}{
+		{
+			"nil error",
+			nil,
+			"",
+		},
+		{
+			"registered error",
+			errors.Wrap(errLogin, "attempts: 3/3"),
+			FailureBMCLogin,
+		},
+		{
+			"unregistered error",
+			errStep,
+			FailureUnknown,
+		},
+		{
+			"context deadline exceeded",
+			errors.Wrap(context.DeadlineExceeded, "upload"),
+			FailureTimeout,
+		},
+		{
+			"failure code retained when the cause is wrapped as a message",
+			errors.Wrap(WrapFailure(errStep, errors.Wrap(errJob, "state: failed")), "plan actions"),
+			FailureBMCJob,
+		},
+		{
+			"failure codes matched in order",
+			multierror.Append(errors.Wrap(errJob, "state: failed"), errLogin),
+			FailureBMCLogin,
+		},
+		{
+			"classified by the terminal error, not the errors in earlier attempts",
+			multierror.Append(
+				errors.Wrap(errLogin, "session lost during BMC reset"),
+				errors.Wrap(errLogin, "session lost during BMC reset"),
+				errors.Wrap(errPollTimeout, "12 attempts querying FirmwareTaskStatus()"),
+			),
+			FailureTimeout,
+		},
+		{
+			"terminal error unclassified, the last classified error is returned",
+			multierror.Append(errors.Wrap(errJob, "state: failed"), errStep),
+			FailureBMCJob,
+		},
+		{
+			"wrapped multierror",
+			errors.Wrap(multierror.Append(errLogin, errors.Wrap(errPollTimeout, "elapsed: 2h")), "pollInstallStatus"),
+			FailureTimeout,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, ClassifyFailure(tc.err))
+		})
+	}
+}
+
+func TestWrapFailure(t *testing.T) {
+	errPlan := errors.New("plan failed")
+
+	// the error chain of an unclassified cause is not changed
+	err := WrapFailure(errPlan, errors.New("firmware set empty"))
+	assert.Equal(t, "firmware set empty: plan failed", err.Error())
+	assert.ErrorIs(t, err, errPlan)
+
+	var failure *Failure
+	assert.False(t, errors.As(err, &failure))
+
+	err = WrapFailure(errPlan, context.DeadlineExceeded)
+	assert.Equal(t, "context deadline exceeded: plan failed", err.Error())
+	assert.ErrorIs(t, err, errPlan)
+	assert.Equal(t, FailureTimeout, ClassifyFailure(err))
+}
@@ -1,9 +1,11 @@
 package model
 
 import (
+	"sort"
 	"strings"
 
 	common "github.com/metal-toolbox/bmc-common"
+	rctypes "github.com/metal-toolbox/rivets/v2/condition"
 )
 
 var (
@@ -24,3 +26,12 @@ var (
 		strings.ToLower(common.SlugCPU):               10,
 	}
 )
+
+// SortFirmwareByInstallOrder sorts the firmware in the order defined by FirmwareInstallOrder.
+func SortFirmwareByInstallOrder(firmwares []*rctypes.Firmware) {
+	sort.SliceStable(firmwares, func(i, j int) bool {
+		slugi := strings.ToLower(firmwares[i].Component)
+		slugj := strings.ToLower(firmwares[j].Component)
+		return FirmwareInstallOrder[slugi] < FirmwareInstallOrder[slugj]
+	})
+}
@@ -0,0 +1,125 @@
+package model
+
+import (
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// ProgressSchemaVersion is the version of the Progress payload published with the task data,
+// this is incremented when a field is renamed, removed or its meaning is changed.
+const ProgressSchemaVersion = 1
+
+// matches the percent complete included in BMC task status messages - 'progress: 45%'
+var percentRegex = regexp.MustCompile(`(\d{1,3})\s*%`)
+
+// ParsePercent returns the percent complete included in a BMC task status message,
+// zero is returned when the status does not include one.
+func ParsePercent(status string) int {
+	matches := percentRegex.FindAllStringSubmatch(status, -1)
+	if len(matches) == 0 {
+		return 0
+	}
+
+	// the last value is the most specific when the status includes more than one
+	percent, err := strconv.Atoi(matches[len(matches)-1][1])
+	if err != nil || percent > 100 {
+		return 0
+	}
+
+	return percent
+}
+
+// Progress is the structured progress of a firmware install, published with the task data.
+type Progress struct {
+	// SchemaVersion is the ProgressSchemaVersion the progress was published with.
+	SchemaVersion int `json:"schemaVersion"`
+
+	// ActionIndex is the position of the current action, starting at 1, out of ActionTotal actions.
+	ActionIndex int `json:"actionIndex"`
+	ActionTotal int `json:"actionTotal"`
+
+	// Component, Version identify the firmware being installed by the current action.
+	Component string `json:"component,omitempty"`
+	Version   string `json:"version,omitempty"`
+
+	// Step is the current, or last step run for the action.
+	Step string `json:"step,omitempty"`
+
+	// BMCTaskID, BMCTaskPercent are the BMC firmware install job and its percent complete as reported by the BMC.
+	BMCTaskID      string `json:"bmcTaskID,omitempty"`
+	BMCTaskPercent int    `json:"bmcTaskPercent,omitempty"`
+
+	// BytesDownloaded, BytesUploaded are the size of the firmware file downloaded, uploaded to the BMC.
+	BytesDownloaded int64 `json:"bytesDownloaded,omitempty"`
+	BytesUploaded   int64 `json:"bytesUploaded,omitempty"`
+
+	// ETA is the estimated completion of the BMC job, based on its percent complete.
+	ETA *time.Time `json:"eta,omitempty"`
+}
+
+// NewProgress returns the install progress of the task,
+// the current action is the first action that is not complete, or the last action once all are complete.
+func NewProgress(task *Task, now time.Time) *Progress {
+	if task.Data == nil || len(task.Data.ActionsPlanned) == 0 {
+		return nil
+	}
+
+	actions := task.Data.ActionsPlanned
+
+	idx := len(actions) - 1
+	for i, action := range actions {
+		if action.State != StateSucceeded && action.State != StateFailed {
+			idx = i
+			break
+		}
+	}
+
+	action := actions[idx]
+
+	progress := &Progress{
+		SchemaVersion:   ProgressSchemaVersion,
+		ActionIndex:     idx + 1,
+		ActionTotal:     len(actions),
+		Component:       action.Firmware.Component,
+		Version:         action.Firmware.Version,
+		BMCTaskID:       action.BMCTaskID,
+		BMCTaskPercent:  action.BMCTaskPercent,
+		BytesDownloaded: action.DownloadedBytes,
+		BytesUploaded:   action.UploadedBytes,
+	}
+
+	step := currentStep(action)
+	if step == nil {
+		return progress
+	}
+
+	progress.Step = string(step.Name)
+
+	// the completion of the BMC job is estimated from the time spent in the step so far
+	if step.State == StateActive && action.BMCTaskPercent > 0 && action.BMCTaskPercent < 100 && !step.StartedAt.IsZero() {
+		elapsed := now.Sub(step.StartedAt)
+		remaining := time.Duration(float64(elapsed) / float64(action.BMCTaskPercent) * float64(100-action.BMCTaskPercent))
+		eta := now.Add(remaining).Round(time.Second)
+		progress.ETA = &eta
+	}
+
+	return progress
+}
+
+// currentStep returns the active step, or the last step that was run.
+func currentStep(action *Action) *Step {
+	var last *Step
+
+	for _, step := range action.Steps {
+		if step.State == StateActive {
+			return step
+		}
+
+		if step.State != StatePending {
+			last = step
+		}
+	}
+
+	return last
+}
@@ -0,0 +1,78 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	rctypes "github.com/metal-toolbox/rivets/v2/condition"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePercent(t *testing.T) {
+	tests := []struct {
+		status string
+		want   int
+	}{
+		{"id: JID_1, state: Running, status: Task successfully scheduled, progress: 45%", 45},
+		{"75%", 75},
+		{"Running", 0},
+		{"progress: 500%", 0},
+		{"", 0},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.status, func(t *testing.T) {
+			assert.Equal(t, tc.want, ParsePercent(tc.status))
+		})
+	}
+}
+
+func TestNewProgress(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 10, 0, 0, time.UTC)
+
+	task := &Task{
+		Data: &TaskData{
+			ActionsPlanned: Actions{
+				{
+					Firmware: rctypes.Firmware{Component: "bios", Version: "2.19.6"},
+					State:    StateSucceeded,
+				},
+				{
+					Firmware:        rctypes.Firmware{Component: "bmc", Version: "7.00.00.00"},
+					State:           StateActive,
+					BMCTaskID:       "JID_1",
+					BMCTaskPercent:  25,
+					DownloadedBytes: 1024,
+					UploadedBytes:   1024,
+					Steps: Steps{
+						{Name: "uploadFirmware", State: StateSucceeded},
+						{
+							Name:   "pollInstallStatus",
+							State:  StateActive,
+							Timing: Timing{StartedAt: now.Add(-5 * time.Minute)},
+						},
+						{Name: "resetBMC", State: StatePending},
+					},
+				},
+			},
+		},
+	}
+
+	got := NewProgress(task, now)
+	require.NotNil(t, got)
+
+	assert.Equal(t, 2, got.ActionIndex)
+	assert.Equal(t, 2, got.ActionTotal)
+	assert.Equal(t, "bmc", got.Component)
+	assert.Equal(t, "pollInstallStatus", got.Step)
+	assert.Equal(t, "JID_1", got.BMCTaskID)
+	assert.Equal(t, int64(1024), got.BytesUploaded)
+
+	// 25% complete in 5 minutes, 15 minutes remaining
+	require.NotNil(t, got.ETA)
+	assert.Equal(t, now.Add(15*time.Minute), *got.ETA)
+
+	// no progress is returned for a task without actions
+	assert.Nil(t, NewProgress(&Task{Data: &TaskData{}}, now))
+}
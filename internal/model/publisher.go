@@ -2,8 +2,11 @@ package model
 
 import (
 	"context"
+	"time"
 
 	"github.com/metal-toolbox/ctrl"
+	"github.com/metal-toolbox/flasher/internal/redact"
+	rctypes "github.com/metal-toolbox/rivets/v2/condition"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 )
@@ -33,6 +36,11 @@ func NewTaskStatusPublisher(logger *logrus.Entry, cp ctrl.Publisher) Publisher {
 }
 
 func (s *StatusPublisher) Publish(ctx context.Context, task *Task) error {
+	// the progress is published with the task data
+	if task.Data != nil {
+		task.Data.Progress = NewProgress(task, time.Now())
+	}
+
 	genericTask, err := CopyAsGenericTask(task)
 	if err != nil {
 		err = errors.Wrap(ErrPublishTask, err.Error())
@@ -46,6 +54,16 @@ func (s *StatusPublisher) Publish(ctx context.Context, task *Task) error {
 	genericTask.Server.BMCPassword = ""
 	genericTask.Server.BMCUser = ""
 
+	// redact sensitive values from the status records,
+	// the records are copied since the slice is shared with the task.
+	statusMsgs := make([]rctypes.StatusMsg, 0, len(genericTask.Status.StatusMsgs))
+	for _, msg := range genericTask.Status.StatusMsgs {
+		msg.Msg = redact.Exported(msg.Msg)
+		statusMsgs = append(statusMsgs, msg)
+	}
+
+	genericTask.Status.StatusMsgs = statusMsgs
+
 	if err := s.cp.Publish(ctx, genericTask, false); err != nil {
 		err = errors.Wrap(ErrPublishStatus, err.Error())
 		s.logger.WithError(err).Error("Condition status publish error")
@@ -0,0 +1,281 @@
+package model
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/metal-toolbox/flasher/internal/redact"
+	rctypes "github.com/metal-toolbox/rivets/v2/condition"
+	"github.com/pkg/errors"
+)
+
+const (
+	// StatusPollSeparator separates a status message from the BMC task status appended to it when polling.
+	StatusPollSeparator = " -- "
+
+	// maxStatusEntries bounds the status history held in memory,
+	// entries after the head are dropped once this is exceeded.
+	maxStatusEntries = 512
+)
+
+var (
+	ErrStatusHistoryParams = errors.New("invalid status history parameters")
+
+	// statusHistoryParams are the parameters applied to each task status history,
+	// this is set to the defaults and can be overridden with SetStatusHistoryParams.
+	statusHistoryParams   = DefaultStatusHistoryParams()
+	statusHistoryParamsMu sync.RWMutex
+)
+
+// StatusHistoryParams defines how the task status history is compacted into the task status record.
+type StatusHistoryParams struct {
+	// MaxBytes is the size budget for the messages in the status record,
+	// when exceeded the messages in between the head and the tail are omitted.
+	MaxBytes int
+
+	// Head is the number of earliest messages always kept in the status record.
+	Head int
+
+	// SpillDir when set, the full status history of each task is appended to a log file in this directory.
+	SpillDir string
+}
+
+// DefaultStatusHistoryParams returns the default status history parameters.
+//
+// The status record messages are kept within 4KiB, the first 2 messages are always kept
+// and the full history is not spilled to a file.
+func DefaultStatusHistoryParams() StatusHistoryParams {
+	return StatusHistoryParams{
+		MaxBytes: 4096,
+		Head:     2,
+	}
+}
+
+// SetStatusHistoryParams overrides the status history parameters,
+// zero values are replaced with the default values.
+func SetStatusHistoryParams(p StatusHistoryParams) error {
+	defaults := DefaultStatusHistoryParams()
+
+	if p.MaxBytes == 0 {
+		p.MaxBytes = defaults.MaxBytes
+	}
+
+	if p.Head == 0 {
+		p.Head = defaults.Head
+	}
+
+	if p.MaxBytes < 0 || p.Head < 0 {
+		return errors.Wrap(ErrStatusHistoryParams, "max bytes, head are expected to be positive values")
+	}
+
+	if p.SpillDir != "" {
+		info, err := os.Stat(p.SpillDir)
+		if err != nil {
+			return errors.Wrap(ErrStatusHistoryParams, err.Error())
+		}
+
+		if !info.IsDir() {
+			return errors.Wrap(ErrStatusHistoryParams, "spill dir is not a directory: "+p.SpillDir)
+		}
+	}
+
+	statusHistoryParamsMu.Lock()
+	defer statusHistoryParamsMu.Unlock()
+
+	statusHistoryParams = p
+
+	return nil
+}
+
+func currentStatusHistoryParams() StatusHistoryParams {
+	statusHistoryParamsMu.RLock()
+	defer statusHistoryParamsMu.RUnlock()
+
+	return statusHistoryParams
+}
+
+// StatusHistory holds the status messages of a task and compacts them into the task status record.
+//
+// Repeated messages and BMC task status poll messages are collapsed into a single entry with a counter,
+// when the messages exceed the size budget, the head and the tail of the history are kept.
+type StatusHistory struct {
+	params    StatusHistoryParams
+	entries   []statusEntry
+	omitted   int
+	spillPath string
+}
+
+type statusEntry struct {
+	timestamp time.Time
+	msg       string
+	count     int
+}
+
+// NewStatusHistory returns a status history for the task, seeded with the messages in the task status record.
+func NewStatusHistory(task *Task) *StatusHistory {
+	h := &StatusHistory{params: currentStatusHistoryParams()}
+
+	if h.params.SpillDir != "" {
+		h.spillPath = filepath.Join(h.params.SpillDir, task.ID.String()+"-status.log")
+	}
+
+	for _, msg := range task.Status.StatusMsgs {
+		h.entries = append(h.entries, statusEntry{timestamp: msg.Timestamp, msg: msg.Msg, count: 1})
+	}
+
+	return h
+}
+
+// Append adds the message to the history and updates the status record.
+func (h *StatusHistory) Append(sr *rctypes.StatusRecord, msg string) {
+	if msg == "" {
+		return
+	}
+
+	h.add(time.Now(), msg, false)
+	h.render(sr)
+}
+
+// AppendPoll adds the BMC task status to the last message in the history and updates the status record,
+// the poll status replaces any previous poll status of the message.
+func (h *StatusHistory) AppendPoll(sr *rctypes.StatusRecord, status string) {
+	if status == "" {
+		return
+	}
+
+	prefix := ""
+	if len(h.entries) > 0 {
+		prefix, _, _ = strings.Cut(h.entries[len(h.entries)-1].msg, StatusPollSeparator)
+	}
+
+	h.add(time.Now(), prefix+StatusPollSeparator+status, true)
+	h.render(sr)
+}
+
+// Last returns the last message in the history, without the counter.
+func (h *StatusHistory) Last() string {
+	if len(h.entries) == 0 {
+		return ""
+	}
+
+	return h.entries[len(h.entries)-1].msg
+}
+
+func (h *StatusHistory) add(ts time.Time, msg string, poll bool) {
+	h.spill(ts, msg)
+
+	if len(h.entries) > 0 {
+		last := &h.entries[len(h.entries)-1]
+
+		if last.msg == msg || poll {
+			last.msg = msg
+			last.timestamp = ts
+			last.count++
+
+			return
+		}
+	}
+
+	h.entries = append(h.entries, statusEntry{timestamp: ts, msg: msg, count: 1})
+
+	// drop the earliest entries after the head when the history grows too large
+	if len(h.entries) > maxStatusEntries {
+		head := min(h.params.Head, maxStatusEntries-1)
+		h.entries = append(h.entries[:head], h.entries[head+1:]...)
+		h.omitted++
+	}
+}
+
+// render writes the head and the tail of the history that fit in the size budget to the status record.
+func (h *StatusHistory) render(sr *rctypes.StatusRecord) {
+	msgs := make([]rctypes.StatusMsg, 0, len(h.entries))
+	for _, entry := range h.entries {
+		msgs = append(msgs, rctypes.StatusMsg{Timestamp: entry.timestamp, Msg: h.truncate(entry.String())})
+	}
+
+	size := 0
+	for _, msg := range msgs {
+		size += len(msg.Msg)
+	}
+
+	if size <= h.params.MaxBytes && h.omitted == 0 {
+		sr.StatusMsgs = msgs
+		return
+	}
+
+	head := min(h.params.Head, len(msgs)-1)
+	budget := h.params.MaxBytes
+
+	for _, msg := range msgs[:head] {
+		budget -= len(msg.Msg)
+	}
+
+	// the tail always includes the last message
+	tail := len(msgs) - 1
+	budget -= len(msgs[tail].Msg)
+
+	for tail > head && budget-len(msgs[tail-1].Msg) >= 0 {
+		tail--
+		budget -= len(msgs[tail].Msg)
+	}
+
+	omitted := h.omitted
+	for _, entry := range h.entries[head:tail] {
+		omitted += entry.count
+	}
+
+	compacted := make([]rctypes.StatusMsg, 0, head+len(msgs)-tail+1)
+	compacted = append(compacted, msgs[:head]...)
+
+	if omitted > 0 {
+		compacted = append(compacted, rctypes.StatusMsg{
+			Timestamp: msgs[tail].Timestamp,
+			Msg:       fmt.Sprintf("... %d status messages omitted ...", omitted),
+		})
+	}
+
+	sr.StatusMsgs = append(compacted, msgs[tail:]...)
+}
+
+// truncate shortens a message to half the size budget so a single message cannot take up the budget.
+func (h *StatusHistory) truncate(msg string) string {
+	maxLen := h.params.MaxBytes / 2
+	if maxLen < 4 || len(msg) <= maxLen {
+		return msg
+	}
+
+	return msg[:maxLen-3] + "..."
+}
+
+// spill appends the message to the status history log file, spilling is disabled on error
+// and the error is added to the history.
+func (h *StatusHistory) spill(ts time.Time, msg string) {
+	if h.spillPath == "" {
+		return
+	}
+
+	fh, err := os.OpenFile(h.spillPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err == nil {
+		_, err = fmt.Fprintf(fh, "%s %s\n", ts.UTC().Format(time.RFC3339), redact.String(msg))
+		if errClose := fh.Close(); err == nil {
+			err = errClose
+		}
+	}
+
+	if err != nil {
+		h.spillPath = ""
+		h.add(ts, "status history spill disabled: "+err.Error(), false)
+	}
+}
+
+func (e statusEntry) String() string {
+	if e.count > 1 {
+		return fmt.Sprintf("%s (x%d)", e.msg, e.count)
+	}
+
+	return e.msg
+}
@@ -0,0 +1,131 @@
+package model
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	rctypes "github.com/metal-toolbox/rivets/v2/condition"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func statusMsgs(sr rctypes.StatusRecord) []string {
+	msgs := make([]string, 0, len(sr.StatusMsgs))
+	for _, msg := range sr.StatusMsgs {
+		msgs = append(msgs, msg.Msg)
+	}
+
+	return msgs
+}
+
+func setStatusHistoryParams(t *testing.T, p StatusHistoryParams) {
+	t.Helper()
+
+	require.Nil(t, SetStatusHistoryParams(p))
+	t.Cleanup(func() {
+		require.Nil(t, SetStatusHistoryParams(DefaultStatusHistoryParams()))
+	})
+}
+
+func TestTaskAppendStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		params StatusHistoryParams
+		append func(task *Task)
+		want   []string
+	}{
+		{
+			"repeated messages collapsed",
+			DefaultStatusHistoryParams(),
+			func(task *Task) {
+				task.AppendStatus("connecting to device BMC")
+				task.AppendStatus("connecting to device BMC")
+				task.AppendStatus("collecting inventory from device BMC")
+			},
+			[]string{"initialized task", "connecting to device BMC (x2)", "collecting inventory from device BMC"},
+		},
+		{
+			"poll messages collapsed",
+			DefaultStatusHistoryParams(),
+			func(task *Task) {
+				task.AppendStatus("bios: installing 2.19.6")
+				task.AppendPollStatus("running 10%")
+				task.AppendPollStatus("running 50%")
+				task.AppendPollStatus("completed 100%")
+			},
+			[]string{"initialized task", "bios: installing 2.19.6 -- completed 100% (x4)"},
+		},
+		{
+			"head, tail kept within budget",
+			StatusHistoryParams{MaxBytes: 40, Head: 1},
+			func(task *Task) {
+				for _, msg := range []string{"msg-1", "msg-2", "msg-3", "msg-4", "msg-5", "msg-6"} {
+					task.AppendStatus(msg)
+				}
+			},
+			[]string{"initialized task", "... 2 status messages omitted ...", "msg-3", "msg-4", "msg-5", "msg-6"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			setStatusHistoryParams(t, tc.params)
+
+			task, err := NewTask(uuid.New(), rctypes.FirmwareInstall, &rctypes.FirmwareInstallTaskParameters{
+				Firmwares: []rctypes.Firmware{{Component: "bios", Version: "2.19.6"}},
+			})
+			require.Nil(t, err)
+
+			tc.append(&task)
+
+			assert.Equal(t, tc.want, statusMsgs(task.Status))
+		})
+	}
+}
+
+func TestStatusHistoryBounded(t *testing.T) {
+	setStatusHistoryParams(t, StatusHistoryParams{MaxBytes: 256, Head: 2})
+
+	task := &Task{ID: uuid.New(), Data: &TaskData{}, Status: rctypes.NewTaskStatusRecord("initialized task")}
+	for i := 0; i < 2*maxStatusEntries; i++ {
+		task.AppendStatus(strings.Repeat("x", i%7) + uuid.NewString())
+	}
+
+	size := 0
+	for _, msg := range statusMsgs(task.Status) {
+		if !strings.HasPrefix(msg, "...") {
+			size += len(msg)
+		}
+	}
+
+	assert.LessOrEqual(t, size, 256)
+	assert.Equal(t, "initialized task", task.Status.StatusMsgs[0].Msg)
+	assert.Len(t, task.Data.statusHistory.entries, maxStatusEntries)
+}
+
+func TestStatusHistorySpill(t *testing.T) {
+	dir := t.TempDir()
+	setStatusHistoryParams(t, StatusHistoryParams{MaxBytes: 20, Head: 1, SpillDir: dir})
+
+	task := &Task{ID: uuid.New(), Data: &TaskData{}}
+	task.AppendStatus("uploading firmware")
+	task.AppendPollStatus("running")
+	task.AppendStatus("verifying installed firmware")
+
+	b, err := os.ReadFile(filepath.Join(dir, task.ID.String()+"-status.log"))
+	require.Nil(t, err)
+
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+	require.Len(t, lines, 3)
+	assert.True(t, strings.HasSuffix(lines[1], "uploading firmware -- running"))
+	assert.True(t, strings.HasSuffix(lines[2], "verifying installed firmware"))
+}
+
+func TestSetStatusHistoryParams(t *testing.T) {
+	assert.ErrorIs(t, SetStatusHistoryParams(StatusHistoryParams{MaxBytes: -1}), ErrStatusHistoryParams)
+	assert.ErrorIs(t, SetStatusHistoryParams(StatusHistoryParams{SpillDir: "/does/not/exist"}), ErrStatusHistoryParams)
+	assert.Equal(t, DefaultStatusHistoryParams(), currentStatusHistoryParams())
+}
@@ -39,6 +39,13 @@ type Step struct {
 	State       rctypes.State `json:"state"`
 	Status      string        `json:"status"`
 	Attempts    int           `json:"attempts"`
+
+	// Interruptible is set for steps that are safe to interrupt when the task deadline is exceeded,
+	// the steps that poll or query the device, other steps are run to completion.
+	Interruptible bool `json:"-"`
+
+	// Timing records the step start, completion and attempts.
+	Timing
 }
 
 func (s *Step) SetState(state rctypes.State) {
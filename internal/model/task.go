@@ -3,6 +3,7 @@ package model
 import (
 	"encoding/json"
 	"reflect"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/mitchellh/copystructure"
@@ -56,6 +57,48 @@ func (t *Task) SetState(s rctypes.State) {
 	t.State = s
 }
 
+// AppendStatus adds the message to the task status history and updates the status record,
+// repeated messages are collapsed and the record is kept within the status history size budget.
+func (t *Task) AppendStatus(msg string) {
+	if h := t.history(); h != nil {
+		h.Append(&t.Status, msg)
+		return
+	}
+
+	t.Status.Append(msg)
+}
+
+// AppendPollStatus adds the BMC task status to the last task status message and updates the status record.
+func (t *Task) AppendPollStatus(status string) {
+	if h := t.history(); h != nil {
+		h.AppendPoll(&t.Status, status)
+		return
+	}
+
+	t.Status.Update(t.Status.Last(), t.Status.Last()+StatusPollSeparator+status)
+}
+
+// LastStatus returns the last task status message.
+func (t *Task) LastStatus() string {
+	if h := t.history(); h != nil {
+		return h.Last()
+	}
+
+	return t.Status.Last()
+}
+
+func (t *Task) history() *StatusHistory {
+	if t.Data == nil {
+		return nil
+	}
+
+	if t.Data.statusHistory == nil {
+		t.Data.statusHistory = NewStatusHistory(t)
+	}
+
+	return t.Data.statusHistory
+}
+
 func (t *Task) MustMarshal() json.RawMessage {
 	b, err := json.Marshal(t)
 	if err != nil {
@@ -79,6 +122,25 @@ type TaskData struct {
 
 	// Scratch is an arbitrary key values map available to all task, action handler methods.
 	Scratch map[string]string `json:"scratch,omitempty"`
+
+	// FailureCode is the machine readable reason the task failed, set when the task fails.
+	FailureCode FailureCode `json:"failure_code,omitempty"`
+
+	// Progress is the install progress, updated each time the task is published.
+	Progress *Progress `json:"progress,omitempty"`
+
+	// Timeout is the optional task timeout parameter, a Go duration string - 2h30m for example.
+	Timeout string `json:"timeout,omitempty"`
+
+	// Deadline is when the task is stopped at the next safe boundary and failed, set when the task is started.
+	Deadline *time.Time `json:"deadline,omitempty"`
+
+	// Log is the task log captured by the worker, published with the final task state.
+	Log string `json:"log,omitempty"`
+
+	// statusHistory is the full status history compacted into the task status record,
+	// this is held in memory and not published.
+	statusHistory *StatusHistory
 }
 
 func (td *TaskData) MapStringInterfaceToStruct(m map[string]interface{}) error {
@@ -192,6 +254,14 @@ func CopyAsFwInstallTask(task *rctypes.Task[any, any]) (*Task, error) {
 		return nil, errors.Wrap(errTaskConv, err.Error())
 	}
 
+	// the timeout parameter is carried in the task data once the task is converted
+	if data.Timeout == "" {
+		data.Timeout, err = convTaskTimeout(task.Parameters)
+		if err != nil {
+			return nil, errors.Wrap(errTaskConv, err.Error())
+		}
+	}
+
 	// deep copy fields referenced by pointer
 	asset, err := copystructure.Copy(task.Server)
 	if err != nil {
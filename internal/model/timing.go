@@ -0,0 +1,77 @@
+package model
+
+import (
+	"time"
+
+	"github.com/metal-toolbox/flasher/internal/redact"
+	"github.com/pkg/errors"
+)
+
+// Timing records when a step or action started and completed, along with the history of its attempts.
+//
+// The start timestamp is of the first attempt and the completed timestamp of the last,
+// the duration spans all attempts including the time in between, when a task was resumed for example.
+type Timing struct {
+	StartedAt       time.Time `json:"started_at"`
+	CompletedAt     time.Time `json:"completed_at"`
+	DurationSeconds float64   `json:"duration_seconds,omitempty"`
+
+	// History lists each attempt in order, with the error if the attempt failed.
+	History []Attempt `json:"history,omitempty"`
+}
+
+// Attempt is a single run of a step or action.
+type Attempt struct {
+	StartedAt       time.Time `json:"started_at"`
+	CompletedAt     time.Time `json:"completed_at"`
+	DurationSeconds float64   `json:"duration_seconds"`
+	Error           string    `json:"error,omitempty"`
+}
+
+// AttemptStarted records the start of an attempt.
+func (t *Timing) AttemptStarted(ts time.Time) {
+	if t.StartedAt.IsZero() {
+		t.StartedAt = ts
+	}
+
+	t.History = append(t.History, Attempt{StartedAt: ts})
+}
+
+// AttemptCompleted records the completion of the current attempt along with its error,
+// the error is redacted as the task data is exported with the task status.
+func (t *Timing) AttemptCompleted(ts time.Time, err error) {
+	t.CompletedAt = ts
+
+	if !t.StartedAt.IsZero() {
+		t.DurationSeconds = ts.Sub(t.StartedAt).Seconds()
+	}
+
+	if len(t.History) == 0 {
+		return
+	}
+
+	attempt := &t.History[len(t.History)-1]
+	attempt.CompletedAt = ts
+	attempt.DurationSeconds = ts.Sub(attempt.StartedAt).Seconds()
+
+	if IsFailure(err) {
+		attempt.Error = redact.Exported(err.Error())
+	}
+}
+
+// Elapsed returns the duration rounded to the millisecond, an empty string is returned if not completed.
+func (t *Timing) Elapsed() string {
+	if t.StartedAt.IsZero() || t.CompletedAt.IsZero() {
+		return ""
+	}
+
+	return t.CompletedAt.Sub(t.StartedAt).Round(time.Millisecond).String()
+}
+
+// IsFailure returns true when the error returned by a step or action is a failure,
+// the installed firmware being equal, or a host power cycle being required are not failures.
+func IsFailure(err error) bool {
+	return err != nil &&
+		!errors.Is(err, ErrInstalledFirmwareEqual) &&
+		!errors.Is(err, ErrHostPowerCycleRequired)
+}
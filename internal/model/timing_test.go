@@ -0,0 +1,77 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/metal-toolbox/flasher/internal/redact"
+	rctypes "github.com/metal-toolbox/rivets/v2/condition"
+	rtypes "github.com/metal-toolbox/rivets/v2/types"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTiming(t *testing.T) {
+	defer redact.Track("", "hunter2-bmc-password")()
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	step := &Step{Name: "pollInstallStatus"}
+	assert.Equal(t, "", step.Elapsed())
+
+	// first attempt fails
+	step.AttemptStarted(start)
+	step.AttemptCompleted(start.Add(time.Minute), errors.New("login with hunter2-bmc-password, BMC returned 500"))
+
+	// the installed firmware being equal is not recorded as an error
+	step.AttemptStarted(start.Add(2 * time.Minute))
+	step.AttemptCompleted(start.Add(5*time.Minute), ErrInstalledFirmwareEqual)
+
+	assert.Equal(t, start, step.StartedAt)
+	assert.Equal(t, start.Add(5*time.Minute), step.CompletedAt)
+	assert.Equal(t, float64(300), step.DurationSeconds)
+	assert.Equal(t, "5m0s", step.Elapsed())
+
+	require.Len(t, step.History, 2)
+	assert.Equal(t, float64(60), step.History[0].DurationSeconds)
+	assert.NotContains(t, step.History[0].Error, "hunter2-bmc-password")
+	assert.Contains(t, step.History[0].Error, "returned 500")
+	assert.Equal(t, float64(180), step.History[1].DurationSeconds)
+	assert.Empty(t, step.History[1].Error)
+}
+
+func TestTimingCopyAsGenericTask(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	task, err := NewTask(uuid.New(), rctypes.FirmwareInstall, &rctypes.FirmwareInstallTaskParameters{
+		Firmwares: []rctypes.Firmware{{Component: "bios", Version: "2.19.6"}},
+	})
+	require.Nil(t, err)
+
+	task.Server = &rtypes.Server{}
+	task.Fault = &rctypes.Fault{}
+
+	step := &Step{Name: "uploadFirmware"}
+	step.AttemptStarted(start)
+	step.AttemptCompleted(start.Add(time.Minute), errors.New("upload failed"))
+
+	action := &Action{ID: "bios-0", Steps: Steps{step}}
+	action.AttemptStarted(start)
+	action.AttemptCompleted(start.Add(time.Minute), errors.New("upload failed"))
+
+	task.Data.ActionsPlanned = Actions{action}
+
+	generic, err := CopyAsGenericTask(&task)
+	require.Nil(t, err)
+
+	got, err := CopyAsFwInstallTask(generic)
+	require.Nil(t, err)
+
+	require.Len(t, got.Data.ActionsPlanned, 1)
+	assert.Equal(t, action.Timing, got.Data.ActionsPlanned[0].Timing)
+
+	require.Len(t, got.Data.ActionsPlanned[0].Steps, 1)
+	assert.Equal(t, step.Timing, got.Data.ActionsPlanned[0].Steps[0].Timing)
+}
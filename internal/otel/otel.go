@@ -23,6 +23,7 @@ import (
 	"time"
 
 	"github.com/go-logr/logr"
+	"github.com/metal-toolbox/flasher/internal/redact"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
 	"go.opentelemetry.io/otel/propagation"
@@ -163,7 +164,8 @@ func (c Config) initTracing(ctx context.Context) (context.Context, context.Cance
 	}
 
 	// TODO: more configuration opportunities here
-	bsp := sdktrace.NewBatchSpanProcessor(exporter)
+	// spans are redacted before export
+	bsp := sdktrace.NewBatchSpanProcessor(redact.NewSpanExporter(exporter))
 	tracerProvider := sdktrace.NewTracerProvider(
 		sdktrace.WithResource(res),
 		sdktrace.WithSpanProcessor(bsp),
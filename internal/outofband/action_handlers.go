@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/hashicorp/go-multierror"
+	"github.com/jpillora/backoff"
 	common "github.com/metal-toolbox/bmc-common"
 	bconsts "github.com/metal-toolbox/bmclib/constants"
 	"github.com/metal-toolbox/flasher/internal/device"
@@ -32,15 +33,6 @@ const (
 	// delay after when the BMC was reset
 	delayBMCReset = 5 * time.Minute
 
-	// delay between polling the firmware install status
-	delayPollStatus = 10 * time.Second
-
-	// maxPollStatusAttempts is set based on how long the loop below should keep polling
-	// for a finalized state before giving up
-	//
-	// 600 (maxAttempts) * 10s (delayPollInstallStatus) = 100 minutes (1.6hours)
-	maxPollStatusAttempts = 600
-
 	// maxVerifyAttempts is the number of times - after a firmware install this poller will spend
 	// attempting to verify the installed firmware equals the expected.
 	//
@@ -48,9 +40,13 @@ const (
 	// the Supermicro BMCs on X12SPO-NTFs, complete the update process, but take
 	// a while to update the installed firmware information returned over redfish.
 	//
-	// 30 (maxVerifyAttempts) * 10 (delayPollStatus) = 300s (5 minutes)
+	// 30 (maxVerifyAttempts) * 10s (delayVerifyPoll) = 300s (5 minutes)
 	maxVerifyAttempts = 30
 
+	// delayVerifyPoll is the delay between attempts to verify the installed firmware,
+	// this is fixed and does not follow the status query backoff.
+	delayVerifyPoll = 10 * time.Second
+
 	// this value indicates the device was powered on by flasher
 	devicePoweredOn = "devicePoweredOn"
 
@@ -59,13 +55,9 @@ const (
 )
 
 var (
-
-	// exponential backoff parameters
-	//
-	// nolint:revive // variable names are clear as is
-	//	backoffMin    = 20 * time.Second
-	//	backoffMax    = 10 * time.Minute
-	//	backoffFactor = 2
+	// pollBackoff holds the parameters applied when polling the BMC for the firmware task status,
+	// this is set to the defaults and can be overridden with SetPollBackoff.
+	pollBackoff = DefaultPollBackoff()
 
 	// envTesting is set by tests to '1' to skip sleeps and backoffs in the handlers.
 	//
@@ -81,8 +73,171 @@ var (
 	ErrInstalledVersionUnknown   = errors.New("installed version unknown")
 	ErrComponentNotFound         = errors.New("component not identified for firmware install")
 	ErrRequireHostPoweredOff     = errors.New("expected host to be powered off")
+	ErrPollStatusTimeout         = errors.New("timeout polling BMC for firmware task status")
+	ErrPollBackoffParams         = errors.New("invalid poll backoff parameters")
 )
 
+func init() {
+	model.RegisterFailureCode(
+		model.FailureBMCLogin,
+		errBMCLogin,
+		errBMCLoginTimeout,
+		errBMCLoginUnAuthorized,
+		errBMCSession,
+		ErrBMCCircuitOpen,
+		ErrBMCCertUntrusted,
+		ErrBMCCertPinMismatch,
+	)
+	model.RegisterFailureCode(model.FailureBMCJob, ErrFirmwareInstallFailed, ErrFirmwareTaskStateUnexpected)
+	model.RegisterFailureCode(model.FailureVerificationMismatch, ErrInstalledFirmwareNotEqual)
+	model.RegisterFailureCode(model.FailureTimeout, ErrPollStatusTimeout)
+}
+
+// PollBackoff defines the jittered exponential backoff parameters
+// for polling the BMC for the firmware task status.
+type PollBackoff struct {
+	// Min is the delay before the first status query.
+	Min time.Duration
+
+	// Max caps the delay between status queries.
+	Max time.Duration
+
+	// Factor is the multiplier applied to the delay for each subsequent status query.
+	Factor float64
+
+	// DisableJitter turns off the randomized delay between status queries,
+	// the jitter spreads out queries when multiple tasks are polling BMCs.
+	DisableJitter bool
+
+	// Timeout is the total time budget for polling a firmware task status before giving up.
+	Timeout time.Duration
+}
+
+// DefaultPollBackoff returns the default firmware task status poll parameters.
+//
+// The delay between status queries starts at 10s and is capped at 2 minutes,
+// the poller gives up after 100 minutes.
+func DefaultPollBackoff() PollBackoff {
+	return PollBackoff{
+		Min:     10 * time.Second,
+		Max:     2 * time.Minute,
+		Factor:  2,
+		Timeout: 100 * time.Minute,
+	}
+}
+
+// SetPollBackoff overrides the firmware task status poll parameters,
+// zero values are replaced with the default values.
+func SetPollBackoff(p PollBackoff) error {
+	defaults := DefaultPollBackoff()
+
+	if p.Min == 0 {
+		p.Min = defaults.Min
+	}
+
+	if p.Max == 0 {
+		p.Max = defaults.Max
+	}
+
+	if p.Factor == 0 {
+		p.Factor = defaults.Factor
+	}
+
+	if p.Timeout == 0 {
+		p.Timeout = defaults.Timeout
+	}
+
+	if p.Min < 0 || p.Max < p.Min {
+		return errors.Wrap(ErrPollBackoffParams, fmt.Sprintf("expected 0 < min <= max, got min: %s, max: %s", p.Min, p.Max))
+	}
+
+	if p.Factor < 1 {
+		return errors.Wrap(ErrPollBackoffParams, fmt.Sprintf("expected factor >= 1, got: %v", p.Factor))
+	}
+
+	if p.Timeout < p.Max {
+		return errors.Wrap(ErrPollBackoffParams, fmt.Sprintf("expected timeout >= max, got timeout: %s, max: %s", p.Timeout, p.Max))
+	}
+
+	pollBackoff = p
+
+	return nil
+}
+
+// pollClock provides the current time and the delay between status queries,
+// the wall clock is used unless the handler is given another clock in tests.
+type pollClock interface {
+	Now() time.Time
+	Sleep(ctx context.Context, d time.Duration) error
+}
+
+type wallClock struct{}
+
+func (wallClock) Now() time.Time {
+	return time.Now()
+}
+
+func (wallClock) Sleep(ctx context.Context, d time.Duration) error {
+	select {
+	case <-time.After(d):
+		return nil
+	case <-ctx.Done():
+		return ErrContextCancelled
+	}
+}
+
+// pollBudget tracks the delay between status queries and the time spent polling.
+type pollBudget struct {
+	params  PollBackoff
+	delay   *backoff.Backoff
+	clock   pollClock
+	startTS time.Time
+}
+
+func newPollBudget(params PollBackoff, clock pollClock) *pollBudget {
+	if clock == nil {
+		clock = wallClock{}
+	}
+
+	return &pollBudget{
+		params: params,
+		delay: &backoff.Backoff{
+			Min:    params.Min,
+			Max:    params.Max,
+			Factor: params.Factor,
+			Jitter: !params.DisableJitter,
+		},
+		clock:   clock,
+		startTS: clock.Now(),
+	}
+}
+
+// next returns the delay before the next status query.
+func (p *pollBudget) next() time.Duration {
+	return p.delay.Duration()
+}
+
+// wait sleeps for the delay before the next status query.
+func (p *pollBudget) wait(ctx context.Context) error {
+	return p.clock.Sleep(ctx, p.next())
+}
+
+// elapsed returns the time spent polling.
+func (p *pollBudget) elapsed() time.Duration {
+	return p.clock.Now().Sub(p.startTS)
+}
+
+// exhausted returns true when the time budget for polling has been spent.
+func (p *pollBudget) exhausted() bool {
+	return p.elapsed() >= p.params.Timeout
+}
+
+// reset restarts the delays and the time budget.
+func (p *pollBudget) reset() {
+	p.delay.Reset()
+	p.startTS = p.clock.Now()
+}
+
 type handler struct {
 	firmware      *rctypes.Firmware
 	task          *model.Task
@@ -90,6 +245,8 @@ type handler struct {
 	deviceQueryor device.OutofbandQueryor
 	publisher     model.Publisher
 	logger        *logrus.Entry
+	// clock is the clock the firmware task status is polled with, the wall clock when nil.
+	clock pollClock
 }
 
 func sleepWithContext(ctx context.Context, t time.Duration) error {
@@ -152,6 +309,8 @@ func (h *handler) powerOnServer(ctx context.Context) error {
 		}).Info("device is currently powered off, powering on")
 
 	if !h.task.Parameters.DryRun {
+		h.action.HostPowerOnInitiated = true
+
 		if err := h.deviceQueryor.SetPowerState(ctx, "on"); err != nil {
 			return err
 		}
@@ -285,8 +444,11 @@ func (h *handler) downloadFirmware(ctx context.Context) error {
 	file := filepath.Join(dir, h.firmware.FileName)
 
 	// download firmware file
+	startTS := time.Now()
+
 	err = download.FromURLToFile(ctx, h.firmware.URL, file)
 	if err != nil {
+		metrics.ObserveDownload(startTS, h.firmware.Component, h.firmware.Vendor, err)
 		return err
 	}
 
@@ -299,10 +461,15 @@ func (h *handler) downloadFirmware(ctx context.Context) error {
 				"vendor":    h.firmware.Vendor,
 			},
 		).Add(float64(fileInfo.Size()))
+
+		h.action.DownloadedBytes = fileInfo.Size()
 	}
 
 	// validate checksum
-	if err := download.ChecksumValidate(file, h.firmware.Checksum); err != nil {
+	err = download.ChecksumValidate(file, h.firmware.Checksum)
+	metrics.ObserveDownload(startTS, h.firmware.Component, h.firmware.Vendor, err)
+
+	if err != nil {
 		os.RemoveAll(filepath.Dir(file))
 		return err
 	}
@@ -333,11 +500,16 @@ func (h *handler) uploadFirmware(ctx context.Context) error {
 
 	if !h.task.Parameters.DryRun {
 		// initiate firmware upload
+		startTS := time.Now()
+
 		firmwareUploadTaskID, err := h.deviceQueryor.FirmwareUpload(
 			ctx,
 			h.firmware.Component,
 			fileHandle,
 		)
+
+		metrics.ObserveUpload(startTS, h.firmware.Component, h.firmware.Vendor, err)
+
 		if err != nil {
 			return err
 		}
@@ -358,6 +530,8 @@ func (h *handler) uploadFirmware(ctx context.Context) error {
 					"vendor":    h.firmware.Vendor,
 				},
 			).Add(float64(fileInfo.Size()))
+
+			h.action.UploadedBytes = fileInfo.Size()
 		}
 	}
 
@@ -387,11 +561,16 @@ func (h *handler) uploadFirmwareInitiateInstall(ctx context.Context) error {
 
 	if !h.task.Parameters.DryRun {
 		// initiate firmware install
+		startTS := time.Now()
+
 		bmcFirmwareInstallTaskID, err := h.deviceQueryor.FirmwareInstallUploadAndInitiate(
 			ctx,
 			h.firmware.Component,
 			fileHandle,
 		)
+
+		metrics.ObserveUpload(startTS, h.firmware.Component, h.firmware.Vendor, err)
+
 		if err != nil {
 			return err
 		}
@@ -405,6 +584,8 @@ func (h *handler) uploadFirmwareInitiateInstall(ctx context.Context) error {
 					"vendor":    h.firmware.Vendor,
 				},
 			).Add(float64(fileInfo.Size()))
+
+			h.action.UploadedBytes = fileInfo.Size()
 		}
 
 		// returned bmcTaskID corresponds to a redfish task ID on BMCs that support redfish
@@ -479,7 +660,8 @@ func (h *handler) pollFirmwareTaskStatus(ctx context.Context) error {
 		installTask = true
 	}
 
-	startTS := time.Now()
+	// tracks the delay between status queries and the total time spent polling
+	budget := newPollBudget(pollBackoff, h.clock)
 
 	// number of status queries attempted
 	var attempts, verifyAttempts int
@@ -502,29 +684,32 @@ func (h *handler) pollFirmwareTaskStatus(ctx context.Context) error {
 			"bmc":         h.task.Server.BMCAddress,
 			"step":        h.action.FirmwareInstallStep,
 			"installTask": installTask,
+			"timeout":     budget.params.Timeout.String(),
 		}).Info("polling BMC for firmware task status")
 
-	// the prefix we'll be using for all the poll status updates
-	statusPrefix := h.task.Status.Last()
-
 	for {
 		// increment attempts
 		attempts++
 
-		// delay if we're in the second or subsequent attempts
-		if attempts > 0 {
-			if err := sleepWithContext(ctx, delayPollStatus); err != nil {
-				return err
-			}
+		// delay before each status query, the delay increases exponentially upto the max backoff interval,
+		// attempts to verify the installed firmware are delayed by the fixed verify interval.
+		wait := budget.wait
+		if inventory {
+			wait = func(ctx context.Context) error { return budget.clock.Sleep(ctx, delayVerifyPoll) }
 		}
 
-		// return when attempts exceed maxPollStatusAttempts
-		if attempts >= maxPollStatusAttempts {
+		if err := wait(ctx); err != nil {
+			return err
+		}
+
+		// return when the time budget for polling has been spent
+		if budget.exhausted() {
 			attemptErrors = multierror.Append(attemptErrors, errors.Wrapf(
-				ErrMaxBMCQueryAttempts,
-				"%d attempts querying FirmwareTaskStatus(), elapsed: %s",
+				ErrPollStatusTimeout,
+				"%d attempts querying FirmwareTaskStatus(), elapsed: %s, timeout: %s",
 				attempts,
-				time.Since(startTS).String(),
+				budget.elapsed().String(),
+				budget.params.Timeout.String(),
 			))
 
 			return attemptErrors
@@ -542,9 +727,9 @@ func (h *handler) pollFirmwareTaskStatus(ctx context.Context) error {
 				h.firmware.Models,
 			)
 
-			// nolint:errorlint // default case catches misc errors
-			switch err {
-			case nil:
+			// the error returned is wrapped with the expected and current versions
+			switch {
+			case err == nil:
 				h.logger.WithFields(
 					logrus.Fields{
 						"bmc":       h.task.Server.BMCAddress,
@@ -553,12 +738,11 @@ func (h *handler) pollFirmwareTaskStatus(ctx context.Context) error {
 
 				return nil
 
-			case ErrInstalledFirmwareNotEqual:
+			case errors.Is(err, ErrInstalledFirmwareNotEqual):
 				// if the BMC came online and is still running the previous version
 				// the install failed
 				if componentIsBMC(h.action.Firmware.Component) && verifyAttempts >= maxVerifyAttempts {
-					errInstall := errors.New("BMC failed to install expected firmware")
-					return errInstall
+					return errors.Wrap(ErrInstalledFirmwareNotEqual, "BMC failed to install expected firmware")
 				}
 
 			default:
@@ -568,8 +752,8 @@ func (h *handler) pollFirmwareTaskStatus(ctx context.Context) error {
 					logrus.Fields{
 						"bmc":       h.task.Server.BMCAddress,
 						"component": h.firmware.Component,
-						"elapsed":   time.Since(startTS).String(),
-						"attempts":  fmt.Sprintf("attempt %d/%d", attempts, maxPollStatusAttempts),
+						"elapsed":   budget.elapsed().String(),
+						"attempts":  attempts,
 						"err":       err.Error(),
 					}).Debug("Inventory collection for component returned error")
 			}
@@ -592,20 +776,24 @@ func (h *handler) pollFirmwareTaskStatus(ctx context.Context) error {
 				"update":    h.firmware.FileName,
 				"version":   h.firmware.Version,
 				"bmc":       h.task.Server.BMCAddress,
-				"elapsed":   time.Since(startTS).String(),
-				"attempts":  fmt.Sprintf("attempt %d/%d", attempts, maxPollStatusAttempts),
+				"elapsed":   budget.elapsed().String(),
+				"attempts":  attempts,
 				"taskState": state,
 				"bmcTaskID": h.action.BMCTaskID,
 				"status":    status,
 			}).Debug("firmware task status query attempt")
 
+		if percent := model.ParsePercent(status); percent > 0 {
+			h.action.BMCTaskPercent = percent
+		}
+
 		if h.publisher != nil && status != "" {
-			h.task.Status.Update(h.task.Status.Last(), statusPrefix+" -- "+status)
+			h.task.AppendPollStatus(status)
 			//nolint:errcheck // method called logs errors if any
 			_ = h.publisher.Publish(ctx, h.task)
 		}
 
-		// error check returns when maxPollStatusAttempts have been reached
+		// error check returns when the time budget for polling has been spent
 		if err != nil {
 			attemptErrors = multierror.Append(attemptErrors, err)
 
@@ -665,8 +853,9 @@ func (h *handler) pollFirmwareTaskStatus(ctx context.Context) error {
 
 			h.action.HostPowerCycled = true
 
-			// reset attempts
+			// reset attempts and the time budget
 			attempts = 0
+			budget.reset()
 
 			continue
 
@@ -825,6 +1014,8 @@ func (h *handler) powerOffServer(ctx context.Context) error {
 				"bmc":       h.task.Server.BMCAddress,
 			}).Debug("powering off device")
 
+		h.action.HostPowerOffInitiated = true
+
 		if err := h.deviceQueryor.SetPowerState(ctx, "off"); err != nil {
 			return err
 		}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"os"
 	"testing"
+	"time"
 
 	common "github.com/metal-toolbox/bmc-common"
 	bconsts "github.com/metal-toolbox/bmclib/constants"
@@ -63,6 +64,7 @@ func TestCheckCurrentFirmware(t *testing.T) {
 		_, err := ah.ComposeAction(context.Background(), actionCtx)
 		assert.Nil(t, err)
 		ah.handler.action.FirmwareInstallStep = string(bconsts.FirmwareInstallStepUploadInitiateInstall)
+		ah.handler.clock = &fakeClock{now: time.Now()}
 
 		return ah.handler, m
 	}
@@ -216,7 +218,7 @@ func TestPollFirmwareInstallStatus(t *testing.T) {
 		{
 			"too many failures, returns error",
 			"unknown",
-			ErrMaxBMCQueryAttempts,
+			ErrPollStatusTimeout,
 		},
 		{
 			"install requires a Host power cycle",
@@ -224,9 +226,9 @@ func TestPollFirmwareInstallStatus(t *testing.T) {
 			nil,
 		},
 		{
-			"install state running exceeds max BMC query attempts",
+			"install state running exceeds poll timeout",
 			"running",
-			ErrMaxBMCQueryAttempts,
+			ErrPollStatusTimeout,
 		},
 		{
 			"install state failed returns error",
@@ -258,6 +260,7 @@ func TestPollFirmwareInstallStatus(t *testing.T) {
 		_, err := ah.ComposeAction(context.Background(), actionCtx)
 		assert.Nil(t, err)
 		ah.handler.action.FirmwareInstallStep = string(bconsts.FirmwareInstallStepUploadInitiateInstall)
+		ah.handler.clock = &fakeClock{now: time.Now()}
 
 		return ah.handler, m
 	}
@@ -296,3 +299,173 @@ func TestPollFirmwareInstallStatus(t *testing.T) {
 		})
 	}
 }
+
+func TestPollFirmwareInstallStatusVerifyBMC(t *testing.T) {
+	actionCtx := newTestActionCtx()
+	actionCtx.Firmware.Component = "bmc"
+
+	m := new(device.MockOutofbandQueryor)
+	m.On("FirmwareInstallSteps", mock.Anything, "bmc").Once().Return(
+		[]bconsts.FirmwareInstallStep{
+			bconsts.FirmwareInstallStepUploadInitiateInstall,
+			bconsts.FirmwareInstallStepInstallStatus,
+		},
+		nil,
+	)
+	actionCtx.DeviceQueryor = m
+
+	ah := &ActionHandler{}
+	_, err := ah.ComposeAction(context.Background(), actionCtx)
+	require.Nil(t, err)
+
+	handler := ah.handler
+	handler.action.FirmwareInstallStep = string(bconsts.FirmwareInstallStepUploadInitiateInstall)
+
+	clock := &fakeClock{now: time.Now()}
+	handler.clock = clock
+
+	// the install runs long enough for the status query delay to reach the max backoff interval,
+	// before the BMC goes unreachable as it applies the firmware.
+	m.EXPECT().FirmwareTaskStatus(mock.Anything, mock.Anything, "bmc", mock.Anything, "DL6R").
+		Times(6).Return(bconsts.Running, "running", nil)
+	m.EXPECT().FirmwareTaskStatus(mock.Anything, mock.Anything, "bmc", mock.Anything, "DL6R").
+		Once().Return(bconsts.TaskState(""), "", errors.New("BMC unreachable"))
+
+	// the BMC comes back on the previous firmware version
+	dev := common.NewDevice()
+	dev.BMC = &common.BMC{
+		Common: common.Common{
+			Vendor:   "Dell-icious",
+			Model:    "r6515",
+			Firmware: &common.Firmware{Installed: "OLDversion"},
+		},
+	}
+
+	var verifyStart time.Time
+	m.EXPECT().Inventory(mock.Anything).
+		Run(func(_ context.Context) {
+			if verifyStart.IsZero() {
+				verifyStart = clock.Now().Add(-delayVerifyPoll)
+			}
+		}).
+		Return(&dev, nil).
+		Times(maxVerifyAttempts)
+
+	err = handler.pollFirmwareTaskStatus(context.Background())
+	assert.ErrorIs(t, err, ErrInstalledFirmwareNotEqual)
+
+	// the installed firmware is verified at the fixed interval, and so gives up after 5 minutes
+	assert.Equal(t, maxVerifyAttempts*delayVerifyPoll, clock.Now().Sub(verifyStart))
+	assert.Equal(t, 5*time.Minute, clock.Now().Sub(verifyStart))
+}
+
+func TestSetPollBackoff(t *testing.T) {
+	testcases := []struct {
+		name     string
+		params   PollBackoff
+		expected PollBackoff
+		err      error
+	}{
+		{
+			"zero values are set to defaults",
+			PollBackoff{},
+			DefaultPollBackoff(),
+			nil,
+		},
+		{
+			"overrides applied",
+			PollBackoff{Min: time.Second, Max: time.Minute, Factor: 1.5, Timeout: time.Hour},
+			PollBackoff{Min: time.Second, Max: time.Minute, Factor: 1.5, Timeout: time.Hour},
+			nil,
+		},
+		{
+			"max less than min",
+			PollBackoff{Min: time.Minute, Max: time.Second},
+			PollBackoff{},
+			ErrPollBackoffParams,
+		},
+		{
+			"factor less than one",
+			PollBackoff{Factor: 0.5},
+			PollBackoff{},
+			ErrPollBackoffParams,
+		},
+		{
+			"timeout less than max",
+			PollBackoff{Max: time.Hour, Timeout: time.Minute},
+			PollBackoff{},
+			ErrPollBackoffParams,
+		},
+	}
+
+	defer func() { pollBackoff = DefaultPollBackoff() }()
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			pollBackoff = DefaultPollBackoff()
+
+			err := SetPollBackoff(tc.params)
+			if tc.err != nil {
+				assert.ErrorIs(t, err, tc.err)
+				assert.Equal(t, DefaultPollBackoff(), pollBackoff)
+				return
+			}
+
+			assert.Nil(t, err)
+			assert.Equal(t, tc.expected, pollBackoff)
+		})
+	}
+}
+
+// fakeClock is a pollClock that advances by the sleep duration instead of sleeping.
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) Sleep(_ context.Context, d time.Duration) error {
+	c.now = c.now.Add(d)
+	return nil
+}
+
+func TestPollBudget(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	budget := newPollBudget(PollBackoff{
+		Min:           10 * time.Second,
+		Max:           time.Minute,
+		Factor:        2,
+		DisableJitter: true,
+		Timeout:       5 * time.Minute,
+	}, clock)
+
+	// delays increase exponentially and are capped at the max interval
+	expected := []time.Duration{
+		10 * time.Second,
+		20 * time.Second,
+		40 * time.Second,
+		time.Minute,
+		time.Minute,
+	}
+
+	for _, want := range expected {
+		assert.False(t, budget.exhausted())
+
+		before := clock.Now()
+		require.Nil(t, budget.wait(context.Background()))
+		assert.Equal(t, want, clock.Now().Sub(before))
+	}
+
+	// 10s + 20s + 40s + 60s + 60s = 3m10s
+	assert.Equal(t, 190*time.Second, budget.elapsed())
+
+	require.Nil(t, budget.wait(context.Background()))
+	require.Nil(t, budget.wait(context.Background()))
+	assert.True(t, budget.exhausted())
+
+	budget.reset()
+	assert.False(t, budget.exhausted())
+	assert.Equal(t, 10*time.Second, budget.next())
+}
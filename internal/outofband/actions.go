@@ -34,6 +34,7 @@ var (
 	errInstallStepsQuery = errors.New("error returned when querying firmware install steps")
 	errNoInstallSteps    = errors.New("no firmware install steps identified")
 	errCompose           = errors.New("error in composing steps for firmware install")
+	errUnsupported       = errors.New("bmclib.FirmwareInstallStep constant not supported")
 )
 
 type ActionHandler struct {
@@ -62,7 +63,7 @@ func (o *ActionHandler) ComposeAction(ctx context.Context, actionCtx *runner.Act
 
 	required, err := deviceQueryor.FirmwareInstallSteps(ctx, actionCtx.Firmware.Component)
 	if err != nil {
-		return nil, errors.Wrap(errInstallStepsQuery, err.Error())
+		return nil, model.WrapFailure(errInstallStepsQuery, err)
 	}
 
 	if len(required) == 0 {
@@ -137,8 +138,6 @@ func (o *ActionHandler) composeSteps(required []bconsts.FirmwareInstallStep, pre
 
 // maps bmclib firmware install steps to transitions
 func (o *ActionHandler) convFirmwareInstallSteps(required []bconsts.FirmwareInstallStep) (model.Steps, error) {
-	errUnsupported := errors.New("bmclib.FirmwareInstallStep constant not supported")
-
 	m := map[bconsts.FirmwareInstallStep]model.StepName{
 		bconsts.FirmwareInstallStepPowerOffHost:          powerOffServer,
 		bconsts.FirmwareInstallStepUpload:                uploadFirmware,
@@ -178,6 +177,16 @@ func (o *ActionHandler) convFirmwareInstallSteps(required []bconsts.FirmwareInst
 	return final, nil
 }
 
+// StepChangesDevice returns true for the steps that leave the device in a changed state when run,
+// the firmware upload, install steps, the BMC reset and the power state steps when a host power on or off was initiated.
+func StepChangesDevice(action *model.Action, step *model.Step) bool {
+	if step.Group == PowerState {
+		return action.HostPowerOnInitiated || action.HostPowerOffInitiated
+	}
+
+	return step.Group == Install || step.Name == preInstallResetBMC
+}
+
 func (o *ActionHandler) definitions() model.Steps {
 	return model.Steps{
 		{
@@ -195,18 +204,20 @@ func (o *ActionHandler) definitions() model.Steps {
 			State:       model.StatePending,
 		},
 		{
-			Name:        checkInstalledFirmware,
-			Group:       PreInstall,
-			Handler:     o.handler.checkCurrentFirmware,
-			Description: "Check firmware currently installed on component",
-			State:       model.StatePending,
+			Name:          checkInstalledFirmware,
+			Group:         PreInstall,
+			Handler:       o.handler.checkCurrentFirmware,
+			Description:   "Check firmware currently installed on component",
+			State:         model.StatePending,
+			Interruptible: true,
 		},
 		{
-			Name:        downloadFirmware,
-			Group:       PreInstall,
-			Handler:     o.handler.downloadFirmware,
-			Description: "Download and verify firmware file checksum.",
-			State:       model.StatePending,
+			Name:          downloadFirmware,
+			Group:         PreInstall,
+			Handler:       o.handler.downloadFirmware,
+			Description:   "Download and verify firmware file checksum.",
+			State:         model.StatePending,
+			Interruptible: true,
 		},
 		{
 			Name:        preInstallResetBMC,
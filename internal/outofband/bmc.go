@@ -53,16 +53,30 @@ type bmc struct {
 	client             *bmclib.Client
 	logger             *logrus.Entry
 	asset              *rtypes.Server
+	registry           *BMCRegistry
+	trust              *TrustStore
+	verified           *verifyResult
 	installProvider    string
 	availableProviders []string
 }
 
 // NewDeviceQueryor returns a bmc queryor that implements the DeviceQueryor interface
 func NewDeviceQueryor(ctx context.Context, asset *rtypes.Server, logger *logrus.Entry) device.OutofbandQueryor {
+	if bmcTrustStore.unconfigured {
+		logger.WithField("bmc", asset.BMCAddress).Warn(
+			"BMC certificate verification is not configured, the BMC certificate will NOT be verified",
+		)
+	}
+
+	client, verified := newBmclibv2Client(ctx, asset, bmcTrustStore, logger)
+
 	return &bmc{
-		client: newBmclibv2Client(ctx, asset, logger),
-		logger: logger,
-		asset:  asset,
+		client:   client,
+		logger:   logger,
+		asset:    asset,
+		registry: bmcRegistry,
+		trust:    bmcTrustStore,
+		verified: verified,
 	}
 }
 
@@ -104,8 +118,7 @@ func (b *bmc) tracelog() {
 }
 
 func (b *bmc) ReinitializeClient(ctx context.Context) {
-	newclient := newBmclibv2Client(ctx, b.asset, b.logger)
-	b.client = newclient
+	b.client, b.verified = newBmclibv2Client(ctx, b.asset, b.trust, b.logger)
 
 	b.logger.WithFields(
 		logrus.Fields{
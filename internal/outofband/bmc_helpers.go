@@ -12,13 +12,13 @@ import (
 	"strings"
 	"time"
 
-	logrusr "github.com/bombsimon/logrusr/v4"
 	"github.com/hashicorp/go-multierror"
 	"github.com/jpillora/backoff"
 	"github.com/metal-toolbox/bmclib"
 	"github.com/metal-toolbox/bmclib/constants"
 	bmcliberrs "github.com/metal-toolbox/bmclib/errors"
 	"github.com/metal-toolbox/bmclib/providers"
+	"github.com/metal-toolbox/flasher/internal/logging"
 	rtypes "github.com/metal-toolbox/rivets/v2/types"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
@@ -46,7 +46,7 @@ func bmcResetParams(steps []constants.FirmwareInstallStep) (bmcResetOnInstallFai
 	return bmcResetOnInstallFailure, bmcResetPostInstall
 }
 
-func newHTTPClient() *http.Client {
+func newHTTPClient(tlsConfig *tls.Config) *http.Client {
 	jar, err := cookiejar.New(&cookiejar.Options{PublicSuffixList: publicsuffix.List})
 	if err != nil {
 		panic(err)
@@ -56,8 +56,7 @@ func newHTTPClient() *http.Client {
 		Timeout: time.Second * 600,
 		Jar:     jar,
 		Transport: &http.Transport{
-			// nolint:gosec // BMCs don't have valid certs.
-			TLSClientConfig:   &tls.Config{InsecureSkipVerify: true},
+			TLSClientConfig:   tlsConfig,
 			DisableKeepAlives: true,
 			Dial: (&net.Dialer{
 				Timeout:   180 * time.Second,
@@ -70,32 +69,20 @@ func newHTTPClient() *http.Client {
 	}
 }
 
-// newBmclibv2Client initializes a bmclib client with the given credentials
-func newBmclibv2Client(_ context.Context, asset *rtypes.Server, l *logrus.Entry) *bmclib.Client {
-	logger := logrus.New()
-	if l != nil {
-		logger.Formatter = l.Logger.Formatter
-		// setup a logr logger for bmclib
-		// bmclib uses logr, for which the trace logs are logged with log.V(3),
-		// this is a hax so the logrusr lib will enable trace logging
-		// since any value that is less than (logrus.LogLevel - 4) >= log.V(3) is ignored
-		// https://github.com/bombsimon/logrusr/blob/master/logrusr.go#L64
-		switch l.Logger.GetLevel() {
-		case logrus.TraceLevel:
-			logger.Level = 7
-		case logrus.DebugLevel:
-			logger.Level = 5
-		}
-	}
+// newBmclibv2Client initializes a bmclib client with the given credentials,
+// the BMC certificate is verified by the given trust store and the verification result for the client connections is returned.
+func newBmclibv2Client(_ context.Context, asset *rtypes.Server, trust *TrustStore, l *logrus.Entry) (*bmclib.Client, *verifyResult) {
+	// the BMC library logs include the task logger fields and are captured in the task log
+	logruslogr := logging.LogrFrom(l, logging.PkgBMCLib)
 
-	logruslogr := logrusr.New(logger)
+	tlsConfig, verified := trust.tlsConfig(asset.BMCAddress)
 
 	bmcClient := bmclib.NewClient(
 		asset.BMCAddress,
 		asset.BMCUser,
 		asset.BMCPassword,
 		bmclib.WithLogger(logruslogr),
-		bmclib.WithHTTPClient(newHTTPClient()),
+		bmclib.WithHTTPClient(newHTTPClient(tlsConfig)),
 		bmclib.WithPerProviderTimeout(loginTimeout),
 		bmclib.WithRedfishEtagMatchDisabled(true),
 		bmclib.WithTracerProvider(otel.GetTracerProvider()),
@@ -106,7 +93,7 @@ func newBmclibv2Client(_ context.Context, asset *rtypes.Server, l *logrus.Entry)
 		providers.FeatureFirmwareInstallSteps,
 	)
 
-	return bmcClient
+	return bmcClient, verified
 }
 
 func (b *bmc) sessionActive(ctx context.Context) error {
@@ -188,6 +175,11 @@ func (b *bmc) loginWithRetries(ctx context.Context, maxAttempts int, provider st
 
 	// loop returns when a session was established or after retries attempts
 	for {
+		// fail fast if the BMC circuit breaker is open, else wait for the BMC request rate limiter
+		if err := b.registry.Acquire(ctx, b.asset.BMCAddress); err != nil {
+			return errors.Wrap(err, errBMCLogin.Error())
+		}
+
 		attemptCtx, cancel := context.WithTimeout(ctx, loginTimeout)
 		// nolint:gocritic // deferInLoop - loop is bounded
 		defer cancel()
@@ -195,6 +187,7 @@ func (b *bmc) loginWithRetries(ctx context.Context, maxAttempts int, provider st
 		// if a session is active, skip login attempt
 		errSessionActive := b.sessionActive(attemptCtx)
 		if errSessionActive == nil {
+			b.registry.Success(b.asset.BMCAddress)
 			return nil
 		}
 
@@ -208,12 +201,34 @@ func (b *bmc) loginWithRetries(ctx context.Context, maxAttempts int, provider st
 				},
 			).Debug("BMC update active, skipping session open attempt")
 
+			b.registry.Success(b.asset.BMCAddress)
+
 			return nil
 		}
 
 		// attempt login
-		errLogin := b.with(provider).Open(attemptCtx)
+		b.verified.reset()
+		errLogin := classifyLoginError(b.with(provider).Open(attemptCtx))
 		if errLogin != nil {
+			// certificate verification failures are not retried,
+			// the error returned by bmclib does not include the verification error and so its looked up here.
+			if errTLS := b.verified.failure(); errTLS != nil {
+				return errors.Wrap(errTLS, errBMCLogin.Error())
+			}
+
+			// stop retrying when repeated failures have tripped the BMC circuit breaker
+			if b.registry.Failure(b.asset.BMCAddress, errLogin) {
+				b.logger.WithFields(
+					logrus.Fields{
+						"provider": provider,
+						"attempt":  fmt.Sprintf("%d/%d", attempts, maxAttempts),
+						"err":      errLogin,
+					},
+				).Warn("bmc circuit breaker open after repeated login failures")
+
+				return errors.Wrap(ErrBMCCircuitOpen, errBMCLogin.Error()+": "+errLogin.Error())
+			}
+
 			var errRetry error
 			// failed to open connection
 			attempts, errRetry = b.retry(ctx, maxAttempts, attempts, errLogin, provider)
@@ -224,6 +239,9 @@ func (b *bmc) loginWithRetries(ctx context.Context, maxAttempts int, provider st
 			continue
 		}
 
+		// the BMC accepted the login, this resolves any probe request when the breaker is half-open
+		b.registry.Success(b.asset.BMCAddress)
+
 		// when we're in middle of a firmware install, the client will lose connection/session,
 		// so now, when the client retries, we want to make sure we have a session
 		// with the installProvider that was identified in FirmwareInstallSteps()
@@ -273,14 +291,6 @@ func (b *bmc) retry(ctx context.Context, maxAttempts, attempts int, cause error,
 
 	// return if attempts match tries
 	if attempts >= maxAttempts {
-		if strings.Contains(cause.Error(), "operation timed out") {
-			cause = multierror.Append(cause, errBMCLoginTimeout)
-		}
-
-		if strings.Contains(cause.Error(), "401: ") || strings.Contains(cause.Error(), "failed to login") {
-			cause = multierror.Append(cause, errBMCLoginUnAuthorized)
-		}
-
 		return 0, errors.Wrapf(errBMCLogin, "attempts: %s, last error: %s", trystr, cause.Error())
 	}
 
@@ -302,6 +312,24 @@ func (b *bmc) retry(ctx context.Context, maxAttempts, attempts int, cause error,
 	return attempts, nil
 }
 
+// classifyLoginError returns the login error along with the errBMCLoginTimeout or errBMCLoginUnAuthorized errors
+// when the error returned by bmclib indicates the login timed out or the credentials were rejected.
+func classifyLoginError(err error) error {
+	if err == nil || errors.Is(err, errBMCLoginTimeout) || errors.Is(err, errBMCLoginUnAuthorized) {
+		return err
+	}
+
+	if strings.Contains(err.Error(), "operation timed out") || errors.Is(err, context.DeadlineExceeded) {
+		return multierror.Append(err, errBMCLoginTimeout)
+	}
+
+	if strings.Contains(err.Error(), "401: ") || strings.Contains(err.Error(), "failed to login") {
+		return multierror.Append(err, errBMCLoginUnAuthorized)
+	}
+
+	return err
+}
+
 func (b *bmc) installProviderAvailable() bool {
 	if b.installProvider == "" {
 		return false
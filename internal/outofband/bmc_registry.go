@@ -0,0 +1,294 @@
+package outofband
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/time/rate"
+)
+
+var (
+	// bmcRegistry is the worker wide registry that coordinates requests to BMCs across tasks,
+	// this is set to the defaults and can be overridden with SetBMCRegistryParams.
+	bmcRegistry = NewBMCRegistry(DefaultBMCRegistryParams())
+
+	// ErrBMCCircuitOpen is returned when a BMC has failed repeatedly and requests to it are suspended.
+	ErrBMCCircuitOpen = errors.New("bmc circuit breaker open, requests to BMC suspended after repeated failures")
+
+	ErrBMCRegistryParams = errors.New("invalid bmc registry parameters")
+)
+
+// BMCRegistryParams defines the request rate and circuit breaker parameters applied to each BMC.
+type BMCRegistryParams struct {
+	// RequestsPerSecond is the rate of requests permitted to a BMC.
+	RequestsPerSecond float64
+
+	// Burst is the number of requests permitted to a BMC in a burst.
+	Burst int
+
+	// FailureThreshold is the number of consecutive login, connection failures after which the breaker trips.
+	FailureThreshold int
+
+	// Cooldown is the time requests to a BMC are suspended once the breaker trips,
+	// after which a single request is let through to probe the BMC.
+	Cooldown time.Duration
+}
+
+// DefaultBMCRegistryParams returns the default BMC request rate and circuit breaker parameters.
+func DefaultBMCRegistryParams() BMCRegistryParams {
+	return BMCRegistryParams{
+		RequestsPerSecond: 1,
+		Burst:             3,
+		FailureThreshold:  6,
+		Cooldown:          5 * time.Minute,
+	}
+}
+
+// SetBMCRegistryParams replaces the worker wide BMC registry with one initialized with the given parameters,
+// zero values are replaced with the default values.
+func SetBMCRegistryParams(p BMCRegistryParams) error {
+	defaults := DefaultBMCRegistryParams()
+
+	if p.RequestsPerSecond == 0 {
+		p.RequestsPerSecond = defaults.RequestsPerSecond
+	}
+
+	if p.Burst == 0 {
+		p.Burst = defaults.Burst
+	}
+
+	if p.FailureThreshold == 0 {
+		p.FailureThreshold = defaults.FailureThreshold
+	}
+
+	if p.Cooldown == 0 {
+		p.Cooldown = defaults.Cooldown
+	}
+
+	if p.RequestsPerSecond < 0 || p.Burst < 0 || p.FailureThreshold < 0 || p.Cooldown < 0 {
+		return errors.Wrap(ErrBMCRegistryParams, "expected positive values")
+	}
+
+	bmcRegistry = NewBMCRegistry(p)
+
+	return nil
+}
+
+type breakerState string
+
+const (
+	breakerClosed   breakerState = "closed"
+	breakerOpen     breakerState = "open"
+	breakerHalfOpen breakerState = "half-open"
+)
+
+// bmcGuard holds the request limiter and circuit breaker state for a single BMC.
+type bmcGuard struct {
+	mu       sync.Mutex
+	limiter  *rate.Limiter
+	state    breakerState
+	failures int
+	openedAt time.Time
+	// lastErr is the failure that tripped the breaker
+	lastErr error
+	// probeInFlight is set when a request was let through to probe the BMC in the half-open state,
+	// further requests fail fast until the probe succeeds or fails.
+	probeInFlight bool
+	probeAt       time.Time
+}
+
+// BMCRegistry coordinates requests to BMCs across tasks running in a worker.
+//
+// Each BMC, identified by its address has a rate limiter and a circuit breaker,
+// the breaker trips after repeated login or connection failures and fails requests fast
+// until the cooldown period has elapsed.
+type BMCRegistry struct {
+	mu     sync.Mutex
+	guards map[string]*bmcGuard
+	params BMCRegistryParams
+	now    func() time.Time
+}
+
+// NewBMCRegistry returns a BMCRegistry with the given parameters.
+func NewBMCRegistry(params BMCRegistryParams) *BMCRegistry {
+	return &BMCRegistry{
+		guards: make(map[string]*bmcGuard),
+		params: params,
+		now:    time.Now,
+	}
+}
+
+func (r *BMCRegistry) guard(addr string) *bmcGuard {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	g, exists := r.guards[addr]
+	if !exists {
+		g = &bmcGuard{
+			limiter: rate.NewLimiter(rate.Limit(r.params.RequestsPerSecond), r.params.Burst),
+			state:   breakerClosed,
+		}
+
+		r.guards[addr] = g
+	}
+
+	return g
+}
+
+// Acquire returns an error if the circuit breaker for the BMC is open,
+// if not it blocks until a request to the BMC is permitted by the rate limiter.
+func (r *BMCRegistry) Acquire(ctx context.Context, addr string) error {
+	g := r.guard(addr)
+
+	if err := r.allow(g); err != nil {
+		return err
+	}
+
+	if err := g.limiter.Wait(ctx); err != nil {
+		// the probe request was not made, let the next request probe the BMC
+		g.mu.Lock()
+		g.probeInFlight = false
+		g.mu.Unlock()
+
+		return err
+	}
+
+	return nil
+}
+
+func (r *BMCRegistry) allow(g *bmcGuard) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	switch g.state {
+	case breakerOpen:
+		remaining := g.openedAt.Add(r.params.Cooldown).Sub(r.now())
+		if remaining > 0 {
+			return errors.Wrap(
+				ErrBMCCircuitOpen,
+				fmt.Sprintf("consecutive failures: %d, retry after: %s, last error: %s",
+					g.failures,
+					remaining.Round(time.Second).String(),
+					g.lastErr,
+				),
+			)
+		}
+
+		// cooldown elapsed, let a request through to probe the BMC
+		g.state = breakerHalfOpen
+		g.probeInFlight = true
+		g.probeAt = r.now()
+
+		return nil
+
+	case breakerHalfOpen:
+		// a probe that was not resolved within the cooldown period is abandoned,
+		// and the next request is let through to probe the BMC.
+		if g.probeInFlight && r.now().Before(g.probeAt.Add(r.params.Cooldown)) {
+			return errors.Wrap(
+				ErrBMCCircuitOpen,
+				fmt.Sprintf("consecutive failures: %d, probe request in flight, last error: %s",
+					g.failures,
+					g.lastErr,
+				),
+			)
+		}
+
+		g.probeInFlight = true
+		g.probeAt = r.now()
+
+		return nil
+
+	default:
+		return nil
+	}
+}
+
+// Success records a successful request to the BMC, this closes the breaker.
+func (r *BMCRegistry) Success(addr string) {
+	g := r.guard(addr)
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.state = breakerClosed
+	g.failures = 0
+	g.lastErr = nil
+	g.probeInFlight = false
+}
+
+// Failure records a failed request to the BMC, failures other than login, connection errors are not counted,
+// the login error is expected to be classified with classifyLoginError.
+//
+// The breaker trips once the failure threshold is reached, or if the probe request in the half-open state fails.
+// Returns true if the breaker is open.
+func (r *BMCRegistry) Failure(addr string, err error) (open bool) {
+	g := r.guard(addr)
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	// the probe request has been resolved
+	g.probeInFlight = false
+
+	if !breakerFailure(err) {
+		return g.state == breakerOpen
+	}
+
+	g.failures++
+	g.lastErr = err
+
+	if g.state == breakerHalfOpen || g.failures >= r.params.FailureThreshold {
+		g.state = breakerOpen
+		g.openedAt = r.now()
+	}
+
+	return g.state == breakerOpen
+}
+
+// circuitState returns the circuit breaker state for the BMC.
+func (r *BMCRegistry) circuitState(addr string) breakerState {
+	g := r.guard(addr)
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.state
+}
+
+// breakerFailure returns true for errors which indicate the BMC is unreachable
+// or is rejecting the credentials - these are the failures counted by the circuit breaker.
+func breakerFailure(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if errors.Is(err, errBMCLoginUnAuthorized) || errors.Is(err, errBMCLoginTimeout) {
+		return true
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+
+	causes := []string{
+		"connection refused",
+		"no route to host",
+		"i/o timeout",
+		"connection reset by peer",
+	}
+
+	for _, cause := range causes {
+		if strings.Contains(err.Error(), cause) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,173 @@
+package outofband
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/metal-toolbox/flasher/internal/model"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBMCRegistryCircuitBreaker(t *testing.T) {
+	addr := "127.0.0.1"
+	errUnauthorized := classifyLoginError(errors.New("401: Unauthorized"))
+
+	now := time.Now()
+	r := NewBMCRegistry(BMCRegistryParams{
+		RequestsPerSecond: 100,
+		Burst:             10,
+		FailureThreshold:  3,
+		Cooldown:          time.Minute,
+	})
+	r.now = func() time.Time { return now }
+
+	ctx := context.Background()
+
+	// failures under the threshold keep the breaker closed
+	assert.False(t, r.Failure(addr, errUnauthorized))
+	assert.False(t, r.Failure(addr, errUnauthorized))
+	assert.Nil(t, r.Acquire(ctx, addr))
+
+	// errors that are not login, connection failures are not counted
+	assert.False(t, r.Failure(addr, errors.New("redfish: unsupported")))
+	assert.Equal(t, breakerClosed, r.circuitState(addr))
+
+	// threshold reached, breaker trips and requests fail fast
+	assert.True(t, r.Failure(addr, errUnauthorized))
+	err := r.Acquire(ctx, addr)
+	assert.ErrorIs(t, err, ErrBMCCircuitOpen)
+	assert.ErrorContains(t, err, "401: Unauthorized")
+
+	// other BMCs are not affected
+	assert.Nil(t, r.Acquire(ctx, "127.0.0.2"))
+
+	// cooldown elapsed, a probe request is let through
+	now = now.Add(2 * time.Minute)
+	assert.Nil(t, r.Acquire(ctx, addr))
+	assert.Equal(t, breakerHalfOpen, r.circuitState(addr))
+
+	// the probe failed, the breaker opens again
+	assert.True(t, r.Failure(addr, errUnauthorized))
+	assert.ErrorIs(t, r.Acquire(ctx, addr), ErrBMCCircuitOpen)
+
+	// the probe succeeded, the breaker is closed
+	now = now.Add(2 * time.Minute)
+	assert.Nil(t, r.Acquire(ctx, addr))
+	r.Success(addr)
+	assert.Equal(t, breakerClosed, r.circuitState(addr))
+	assert.False(t, r.Failure(addr, errUnauthorized))
+}
+
+func TestBMCRegistryHalfOpenSingleProbe(t *testing.T) {
+	addr := "127.0.0.1"
+	errTimeout := classifyLoginError(errors.New("operation timed out"))
+
+	now := time.Now()
+	r := NewBMCRegistry(BMCRegistryParams{
+		RequestsPerSecond: 1000,
+		Burst:             100,
+		FailureThreshold:  1,
+		Cooldown:          time.Minute,
+	})
+	r.now = func() time.Time { return now }
+
+	assert.True(t, r.Failure(addr, errTimeout))
+
+	// cooldown elapsed, concurrent requests race to probe the BMC
+	now = now.Add(2 * time.Minute)
+
+	var probes, rejected atomic.Int32
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			err := r.Acquire(context.Background(), addr)
+			if err == nil {
+				probes.Add(1)
+				return
+			}
+
+			if errors.Is(err, ErrBMCCircuitOpen) {
+				rejected.Add(1)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	// a single probe is let through, the others fail fast
+	assert.Equal(t, int32(1), probes.Load())
+	assert.Equal(t, int32(19), rejected.Load())
+	assert.Equal(t, breakerHalfOpen, r.circuitState(addr))
+
+	// requests fail fast until the probe is resolved
+	assert.ErrorIs(t, r.Acquire(context.Background(), addr), ErrBMCCircuitOpen)
+
+	r.Success(addr)
+	assert.Nil(t, r.Acquire(context.Background(), addr))
+
+	// an unresolved probe is abandoned after the cooldown period
+	assert.True(t, r.Failure(addr, errTimeout))
+	now = now.Add(2 * time.Minute)
+	assert.Nil(t, r.Acquire(context.Background(), addr))
+	assert.ErrorIs(t, r.Acquire(context.Background(), addr), ErrBMCCircuitOpen)
+
+	now = now.Add(2 * time.Minute)
+	assert.Nil(t, r.Acquire(context.Background(), addr))
+}
+
+func TestBMCRegistryLoginUnauthorized(t *testing.T) {
+	addr := "127.0.0.1"
+
+	// the error returned by bmclib when each provider rejects the credentials
+	errLogin := errors.New("failed to open connection: provider: gofish: 401: Unauthorized")
+
+	r := NewBMCRegistry(BMCRegistryParams{
+		RequestsPerSecond: 100,
+		Burst:             10,
+		FailureThreshold:  2,
+		Cooldown:          time.Minute,
+	})
+
+	classified := classifyLoginError(errLogin)
+	assert.ErrorIs(t, classified, errBMCLoginUnAuthorized)
+	assert.Equal(t, model.FailureBMCLogin, model.ClassifyFailure(classified))
+
+	// the unauthorized login failures trip the breaker
+	assert.False(t, r.Failure(addr, classified))
+	assert.True(t, r.Failure(addr, classifyLoginError(errLogin)))
+	assert.ErrorIs(t, r.Acquire(context.Background(), addr), ErrBMCCircuitOpen)
+
+	// errors not classified as login, connection failures are not counted
+	assert.False(t, NewBMCRegistry(DefaultBMCRegistryParams()).Failure(addr, classifyLoginError(errors.New("redfish: unsupported"))))
+}
+
+func TestBMCRegistryRateLimit(t *testing.T) {
+	addr := "127.0.0.1"
+	r := NewBMCRegistry(BMCRegistryParams{
+		RequestsPerSecond: 1,
+		Burst:             1,
+		FailureThreshold:  3,
+		Cooldown:          time.Minute,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	// the burst is permitted
+	assert.Nil(t, r.Acquire(ctx, addr))
+
+	// the next request would exceed the context deadline
+	assert.Error(t, r.Acquire(ctx, addr))
+
+	// other BMCs have their own limiter
+	assert.Nil(t, r.Acquire(ctx, "127.0.0.2"))
+}
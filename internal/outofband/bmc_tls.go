@@ -0,0 +1,299 @@
+package outofband
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	// bmcTrustStore verifies the certificates presented by BMCs, this is set with SetBMCTLSParams.
+	//
+	// Without the TLS parameters configured for the facility, the BMC certificate is not verified
+	// and a warning is logged on each connection.
+	bmcTrustStore = &TrustStore{params: TLSParams{InsecureSkipVerify: true}, unconfigured: true}
+
+	// ErrBMCCertUntrusted is returned when the BMC certificate could not be verified with the CA bundle
+	// and was not pinned.
+	ErrBMCCertUntrusted = errors.New("bmc certificate untrusted")
+
+	// ErrBMCCertPinMismatch is returned when the BMC certificate does not match the fingerprint pinned for the BMC.
+	ErrBMCCertPinMismatch = errors.New("bmc certificate does not match pinned fingerprint")
+
+	// ErrBMCTrustStore is returned when the trust store configuration or pin store is invalid.
+	ErrBMCTrustStore = errors.New("bmc trust store error")
+)
+
+// TLSParams defines how certificates presented by BMCs are verified.
+type TLSParams struct {
+	// InsecureSkipVerify disables BMC certificate verification, this must be explicitly set.
+	InsecureSkipVerify bool
+
+	// CABundle is the path to a PEM encoded CA bundle used to verify BMC certificates.
+	CABundle string
+
+	// PinStore is the path to the file where BMC certificate fingerprints are persisted.
+	PinStore string
+
+	// TrustOnFirstUse pins the certificate fingerprint presented by a BMC on the first connection,
+	// subsequent connections require the BMC to present the same certificate.
+	TrustOnFirstUse bool
+}
+
+// TrustStore verifies BMC certificates against a CA bundle and/or per BMC certificate fingerprint pins.
+type TrustStore struct {
+	mu     sync.Mutex
+	params TLSParams
+	roots  *x509.CertPool
+	// pins is a map of BMC addresses to the sha256 fingerprint of the certificate.
+	pins map[string]string
+	// unconfigured is set for the default trust store, which skips verification.
+	unconfigured bool
+}
+
+// SetBMCTLSParams replaces the worker wide BMC trust store with one initialized with the given parameters.
+func SetBMCTLSParams(p TLSParams) error {
+	store, err := NewTrustStore(p)
+	if err != nil {
+		return err
+	}
+
+	bmcTrustStore = store
+
+	return nil
+}
+
+// NewTrustStore returns a TrustStore initialized with the CA bundle and the pins persisted in the pin store.
+func NewTrustStore(p TLSParams) (*TrustStore, error) {
+	store := &TrustStore{
+		params: p,
+		pins:   make(map[string]string),
+	}
+
+	if p.InsecureSkipVerify {
+		return store, nil
+	}
+
+	if p.CABundle == "" && p.PinStore == "" {
+		return nil, errors.Wrap(ErrBMCTrustStore, "expected a CA bundle and/or a pin store when certificate verification is enabled")
+	}
+
+	if p.TrustOnFirstUse && p.PinStore == "" {
+		return nil, errors.Wrap(ErrBMCTrustStore, "trust on first use requires a pin store")
+	}
+
+	if p.CABundle != "" {
+		pem, err := os.ReadFile(p.CABundle)
+		if err != nil {
+			return nil, errors.Wrap(ErrBMCTrustStore, "CA bundle: "+err.Error())
+		}
+
+		store.roots = x509.NewCertPool()
+		if !store.roots.AppendCertsFromPEM(pem) {
+			return nil, errors.Wrap(ErrBMCTrustStore, "CA bundle: no certificates found in "+p.CABundle)
+		}
+	}
+
+	if p.PinStore != "" {
+		if err := store.loadPins(); err != nil {
+			return nil, err
+		}
+	}
+
+	return store, nil
+}
+
+// verifyResult holds the certificate verification failure for the connections made with a single tls configuration,
+// this is returned in place of the errors reported by bmclib, which does not preserve error types.
+//
+// Each bmclib client is given its own tls configuration, so clients connecting to the same BMC
+// do not report or clear each others verification failures.
+type verifyResult struct {
+	mu  sync.Mutex
+	err error
+}
+
+func (r *verifyResult) set(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.err = err
+}
+
+// failure returns the certificate verification failure in the current connection attempt.
+func (r *verifyResult) failure() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.err
+}
+
+// reset clears the verification failure, this is invoked before each connection attempt
+// so a failure is not reported for a later attempt that failed for another reason.
+func (r *verifyResult) reset() {
+	r.set(nil)
+}
+
+// tlsConfig returns the tls configuration for connections to the BMC at the given address,
+// along with the result of the certificate verification for the connections made with the configuration.
+func (s *TrustStore) tlsConfig(addr string) (*tls.Config, *verifyResult) {
+	result := &verifyResult{}
+
+	if s.params.InsecureSkipVerify {
+		// nolint:gosec // insecure mode is explicitly configured.
+		return &tls.Config{InsecureSkipVerify: true}, result
+	}
+
+	// nolint:gosec // the default verification is replaced by VerifyConnection,
+	// BMC certificates are verified against the CA bundle and pinned fingerprints.
+	return &tls.Config{
+		InsecureSkipVerify: true,
+		VerifyConnection: func(cs tls.ConnectionState) error {
+			err := s.verifyPeer(addr, &cs)
+			result.set(err)
+
+			return err
+		},
+	}, result
+}
+
+func (s *TrustStore) verifyPeer(addr string, cs *tls.ConnectionState) error {
+	if len(cs.PeerCertificates) == 0 {
+		return errors.Wrap(ErrBMCCertUntrusted, "no certificate presented")
+	}
+
+	leaf := cs.PeerCertificates[0]
+
+	var errChain error
+	if s.roots != nil {
+		intermediates := x509.NewCertPool()
+		for _, cert := range cs.PeerCertificates[1:] {
+			intermediates.AddCert(cert)
+		}
+
+		_, errChain = leaf.Verify(x509.VerifyOptions{
+			Roots:         s.roots,
+			Intermediates: intermediates,
+			DNSName:       hostname(addr),
+		})
+
+		if errChain == nil {
+			return nil
+		}
+	}
+
+	if s.params.PinStore == "" {
+		return errors.Wrap(ErrBMCCertUntrusted, errChain.Error())
+	}
+
+	fingerprint := certFingerprint(leaf)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pinned, exists := s.pins[addr]
+
+	switch {
+	case exists && pinned == fingerprint:
+		return nil
+
+	case exists:
+		return errors.Wrap(ErrBMCCertPinMismatch, fmt.Sprintf("pinned: %s, presented: %s", pinned, fingerprint))
+
+	case s.params.TrustOnFirstUse:
+		s.pins[addr] = fingerprint
+		if err := s.persistPins(); err != nil {
+			// the certificate is not trusted unless the pin was persisted
+			delete(s.pins, addr)
+			return err
+		}
+
+		return nil
+
+	default:
+		msg := "certificate not pinned: " + fingerprint
+		if errChain != nil {
+			msg += ", CA verification: " + errChain.Error()
+		}
+
+		return errors.Wrap(ErrBMCCertUntrusted, msg)
+	}
+}
+
+func (s *TrustStore) loadPins() error {
+	b, err := os.ReadFile(s.params.PinStore)
+	if err != nil {
+		// the pin store is created when the first pin is persisted
+		if os.IsNotExist(err) {
+			return nil
+		}
+
+		return errors.Wrap(ErrBMCTrustStore, "pin store: "+err.Error())
+	}
+
+	if len(b) == 0 {
+		return nil
+	}
+
+	if err := json.Unmarshal(b, &s.pins); err != nil {
+		return errors.Wrap(ErrBMCTrustStore, "pin store: "+err.Error())
+	}
+
+	return nil
+}
+
+// persistPins writes the pins into the pin store, the caller is expected to hold the lock.
+func (s *TrustStore) persistPins() error {
+	b, err := json.MarshalIndent(s.pins, "", "  ")
+	if err != nil {
+		return errors.Wrap(ErrBMCTrustStore, "pin store: "+err.Error())
+	}
+
+	// write into a temp file and rename, so the pin store is never partially written
+	tmp, err := os.CreateTemp(filepath.Dir(s.params.PinStore), ".pins-")
+	if err != nil {
+		return errors.Wrap(ErrBMCTrustStore, "pin store: "+err.Error())
+	}
+
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return errors.Wrap(ErrBMCTrustStore, "pin store: "+err.Error())
+	}
+
+	if err := tmp.Close(); err != nil {
+		return errors.Wrap(ErrBMCTrustStore, "pin store: "+err.Error())
+	}
+
+	if err := os.Rename(tmp.Name(), s.params.PinStore); err != nil {
+		return errors.Wrap(ErrBMCTrustStore, "pin store: "+err.Error())
+	}
+
+	return nil
+}
+
+// certFingerprint returns the sha256 fingerprint of the certificate.
+func certFingerprint(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.Raw)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// hostname returns the host part of the BMC address.
+func hostname(addr string) string {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+
+	return host
+}
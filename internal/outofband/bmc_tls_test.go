@@ -0,0 +1,230 @@
+package outofband
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrustStore(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	addr := strings.TrimPrefix(srv.URL, "https://")
+
+	// the test server certificate is self signed
+	caBundle := filepath.Join(t.TempDir(), "ca.pem")
+	err := os.WriteFile(caBundle, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw}), 0o600)
+	require.Nil(t, err)
+
+	// a CA bundle that does not include the test server certificate
+	otherCABundle := filepath.Join(t.TempDir(), "other-ca.pem")
+	err = os.WriteFile(otherCABundle, selfSignedCertPEM(t), 0o600)
+	require.Nil(t, err)
+
+	get := func(store *TrustStore) (*verifyResult, error) {
+		tlsConfig, verified := store.tlsConfig(addr)
+
+		resp, err := newHTTPClient(tlsConfig).Get(srv.URL)
+		if err != nil {
+			return verified, err
+		}
+
+		resp.Body.Close()
+
+		return verified, nil
+	}
+
+	testcases := []struct {
+		name       string
+		params     func(pinStore string) TLSParams
+		pins       string
+		wantErr    error
+		wantPinned bool
+	}{
+		{
+			"insecure",
+			func(_ string) TLSParams { return TLSParams{InsecureSkipVerify: true} },
+			"",
+			nil,
+			false,
+		},
+		{
+			"verified with CA bundle",
+			func(_ string) TLSParams { return TLSParams{CABundle: caBundle} },
+			"",
+			nil,
+			false,
+		},
+		{
+			"CA bundle does not verify certificate",
+			func(_ string) TLSParams { return TLSParams{CABundle: otherCABundle} },
+			"",
+			ErrBMCCertUntrusted,
+			false,
+		},
+		{
+			"certificate not pinned",
+			func(pinStore string) TLSParams { return TLSParams{PinStore: pinStore} },
+			"",
+			ErrBMCCertUntrusted,
+			false,
+		},
+		{
+			"certificate pinned on first use",
+			func(pinStore string) TLSParams { return TLSParams{PinStore: pinStore, TrustOnFirstUse: true} },
+			"",
+			nil,
+			true,
+		},
+		{
+			"certificate pin mismatch",
+			func(pinStore string) TLSParams { return TLSParams{PinStore: pinStore, TrustOnFirstUse: true} },
+			`{"` + addr + `": "sha256:beef"}`,
+			ErrBMCCertPinMismatch,
+			false,
+		},
+		{
+			"CA bundle fails, pin matches",
+			func(pinStore string) TLSParams {
+				return TLSParams{CABundle: otherCABundle, PinStore: pinStore}
+			},
+			`{"` + addr + `": "` + certFingerprint(srv.Certificate()) + `"}`,
+			nil,
+			true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			pinStore := filepath.Join(t.TempDir(), "pins.json")
+			if tc.pins != "" {
+				require.Nil(t, os.WriteFile(pinStore, []byte(tc.pins), 0o600))
+			}
+
+			store, err := NewTrustStore(tc.params(pinStore))
+			require.Nil(t, err)
+
+			verified, err := get(store)
+			if tc.wantErr != nil {
+				assert.NotNil(t, err)
+				assert.True(t, errors.Is(verified.failure(), tc.wantErr), verified.failure())
+				return
+			}
+
+			assert.Nil(t, err)
+			assert.Nil(t, verified.failure())
+
+			if tc.wantPinned {
+				// pins are loaded from the pin store
+				reloaded, err := NewTrustStore(tc.params(pinStore))
+				require.Nil(t, err)
+				assert.Equal(t, certFingerprint(srv.Certificate()), reloaded.pins[addr])
+			}
+		})
+	}
+}
+
+func TestTrustStoreFailureScope(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	addr := strings.TrimPrefix(srv.URL, "https://")
+
+	// the pin store directory does not exist, and so the pin is not persisted
+	store, err := NewTrustStore(TLSParams{
+		PinStore:        filepath.Join(t.TempDir(), "missing", "pins.json"),
+		TrustOnFirstUse: true,
+	})
+	require.Nil(t, err)
+
+	// a client connecting to the same BMC, whose connection has not failed verification
+	_, other := store.tlsConfig(addr)
+
+	tlsConfig, verified := store.tlsConfig(addr)
+
+	resp, err := newHTTPClient(tlsConfig).Get(srv.URL)
+	if err == nil {
+		resp.Body.Close()
+	}
+
+	assert.NotNil(t, err)
+	assert.ErrorIs(t, verified.failure(), ErrBMCTrustStore)
+
+	// the failure is not reported for the other client
+	assert.Nil(t, other.failure())
+
+	// the pin was rolled back
+	assert.Empty(t, store.pins)
+
+	// the failure is cleared before the next connection attempt,
+	// so a later login failure is not reported as a certificate failure.
+	verified.reset()
+	assert.Nil(t, verified.failure())
+}
+
+func TestNewTrustStoreParams(t *testing.T) {
+	testcases := []struct {
+		name    string
+		params  TLSParams
+		wantErr error
+	}{
+		{"insecure", TLSParams{InsecureSkipVerify: true}, nil},
+		{"no CA bundle or pin store", TLSParams{}, ErrBMCTrustStore},
+		{"trust on first use without pin store", TLSParams{CABundle: "ca.pem", TrustOnFirstUse: true}, ErrBMCTrustStore},
+		{"CA bundle does not exist", TLSParams{CABundle: "/does/not/exist.pem"}, ErrBMCTrustStore},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewTrustStore(tc.params)
+			if tc.wantErr != nil {
+				assert.ErrorIs(t, err, tc.wantErr)
+				return
+			}
+
+			assert.Nil(t, err)
+		})
+	}
+}
+
+func selfSignedCertPEM(t *testing.T) []byte {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.Nil(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.Nil(t, err)
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
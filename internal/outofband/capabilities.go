@@ -0,0 +1,101 @@
+package outofband
+
+import (
+	"context"
+
+	"github.com/metal-toolbox/flasher/internal/device"
+	"github.com/metal-toolbox/flasher/internal/model"
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrCapabilities = errors.New("error querying BMC firmware install capabilities")
+)
+
+// Capability is the BMC firmware install support for a component.
+type Capability struct {
+	Component string `json:"component" yaml:"component"`
+	Vendor    string `json:"vendor" yaml:"vendor"`
+	Model     string `json:"model" yaml:"model"`
+	Supported bool   `json:"supported" yaml:"supported"`
+	// Reason is set when the component firmware install is not supported.
+	Reason string `json:"reason,omitempty" yaml:"reason,omitempty"`
+	// BMCInstallSteps are the firmware install steps as returned by the BMC.
+	BMCInstallSteps []string `json:"bmc_install_steps" yaml:"bmc_install_steps"`
+	// Steps are the flasher install steps the BMC install steps are mapped to.
+	Steps                    []model.StepName `json:"steps" yaml:"steps"`
+	HostPowerOffPreInstall   bool             `json:"host_power_off_pre_install" yaml:"host_power_off_pre_install"`
+	BMCResetPostInstall      bool             `json:"bmc_reset_post_install" yaml:"bmc_reset_post_install"`
+	BMCResetOnInstallFailure bool             `json:"bmc_reset_on_install_failure" yaml:"bmc_reset_on_install_failure"`
+}
+
+// Capabilities queries the BMC for the firmware install steps of each component in the device inventory,
+// and returns the install steps flasher would run for each component, or the reason the component is not supported.
+func Capabilities(ctx context.Context, queryor device.OutofbandQueryor) ([]*Capability, error) {
+	if err := queryor.Open(ctx); err != nil {
+		return nil, errors.Wrap(ErrCapabilities, err.Error())
+	}
+
+	// nolint:errcheck // the capabilities are collected by this point
+	defer queryor.Close(ctx)
+
+	dev, err := queryor.Inventory(ctx)
+	if err != nil {
+		return nil, errors.Wrap(ErrCapabilities, err.Error())
+	}
+
+	components, err := model.NewComponentConverter().CommonDeviceToComponents(dev)
+	if err != nil {
+		return nil, errors.Wrap(ErrCapabilities, err.Error())
+	}
+
+	o := &ActionHandler{handler: &handler{}}
+
+	capabilities := []*Capability{}
+	seen := map[string]bool{}
+
+	for _, component := range components {
+		// install steps are queried by the component slug, components of the same kind are listed once.
+		if seen[component.Name] {
+			continue
+		}
+
+		seen[component.Name] = true
+
+		capability := &Capability{
+			Component:       component.Name,
+			Vendor:          component.Vendor,
+			Model:           component.Model,
+			BMCInstallSteps: []string{},
+			Steps:           []model.StepName{},
+		}
+
+		capabilities = append(capabilities, capability)
+
+		required, err := queryor.FirmwareInstallSteps(ctx, component.Name)
+		if err != nil {
+			capability.Reason = errors.Wrap(errInstallStepsQuery, err.Error()).Error()
+			continue
+		}
+
+		for _, s := range required {
+			capability.BMCInstallSteps = append(capability.BMCInstallSteps, string(s))
+		}
+
+		steps, err := o.convFirmwareInstallSteps(required)
+		if err != nil {
+			capability.Reason = err.Error()
+			continue
+		}
+
+		for _, step := range steps {
+			capability.Steps = append(capability.Steps, step.Name)
+		}
+
+		capability.Supported = true
+		capability.HostPowerOffPreInstall = hostPowerOffRequired(required)
+		capability.BMCResetOnInstallFailure, capability.BMCResetPostInstall = bmcResetParams(required)
+	}
+
+	return capabilities, nil
+}
@@ -0,0 +1,106 @@
+package outofband
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	common "github.com/metal-toolbox/bmc-common"
+	bconsts "github.com/metal-toolbox/bmclib/constants"
+	"github.com/metal-toolbox/flasher/internal/device"
+	"github.com/metal-toolbox/flasher/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCapabilities(t *testing.T) {
+	ctx := context.Background()
+
+	dev := common.NewDevice()
+	dev.Model = "PowerEdge R6515"
+	dev.Vendor = "Dell"
+	dev.BIOS = &common.BIOS{Common: common.Common{Firmware: &common.Firmware{Installed: "2.6.6"}}}
+	dev.BMC = &common.BMC{Common: common.Common{Vendor: "Dell", Firmware: &common.Firmware{Installed: "6.10.30.00"}}}
+
+	t.Run("capabilities listed", func(t *testing.T) {
+		dq := device.NewMockOutofbandQueryor(t)
+		dq.EXPECT().Open(mock.Anything).Return(nil)
+		dq.EXPECT().Inventory(mock.Anything).Return(&dev, nil)
+		dq.EXPECT().Close(mock.Anything).Return(nil)
+
+		dq.EXPECT().FirmwareInstallSteps(mock.Anything, "bios").Return(
+			[]bconsts.FirmwareInstallStep{
+				bconsts.FirmwareInstallStepPowerOffHost,
+				bconsts.FirmwareInstallStepUploadInitiateInstall,
+				bconsts.FirmwareInstallStepInstallStatus,
+			},
+			nil,
+		)
+
+		dq.EXPECT().FirmwareInstallSteps(mock.Anything, "bmc").Return(
+			[]bconsts.FirmwareInstallStep{
+				bconsts.FirmwareInstallStepUpload,
+				bconsts.FirmwareInstallStepUploadStatus,
+				bconsts.FirmwareInstallStepInstallUploaded,
+				bconsts.FirmwareInstallStepInstallStatus,
+				bconsts.FirmwareInstallStepResetBMCPostInstall,
+			},
+			nil,
+		)
+
+		dq.EXPECT().FirmwareInstallSteps(mock.Anything, "mainboard").Return(
+			[]bconsts.FirmwareInstallStep{"foobar"},
+			nil,
+		)
+
+		capabilities, err := Capabilities(ctx, dq)
+		require.Nil(t, err)
+		require.Len(t, capabilities, 3)
+
+		bios := capabilities[0]
+		assert.Equal(t, "bios", bios.Component)
+		assert.True(t, bios.Supported)
+		assert.True(t, bios.HostPowerOffPreInstall)
+		assert.False(t, bios.BMCResetPostInstall)
+		assert.Equal(t, []model.StepName{powerOffServer, uploadFirmwareInitiateInstall, pollInstallStatus}, bios.Steps)
+
+		bmc := capabilities[1]
+		assert.Equal(t, "bmc", bmc.Component)
+		assert.True(t, bmc.Supported)
+		assert.False(t, bmc.HostPowerOffPreInstall)
+		assert.True(t, bmc.BMCResetPostInstall)
+		assert.Len(t, bmc.BMCInstallSteps, 5)
+		assert.Equal(t, []model.StepName{uploadFirmware, pollUploadStatus, installUploadedFirmware, pollInstallStatus}, bmc.Steps)
+
+		mainboard := capabilities[2]
+		assert.Equal(t, "mainboard", mainboard.Component)
+		assert.False(t, mainboard.Supported)
+		assert.Contains(t, mainboard.Reason, errUnsupported.Error())
+		assert.Empty(t, mainboard.Steps)
+	})
+
+	t.Run("install steps query error", func(t *testing.T) {
+		dq := device.NewMockOutofbandQueryor(t)
+		dq.EXPECT().Open(mock.Anything).Return(nil)
+		dq.EXPECT().Inventory(mock.Anything).Return(&dev, nil)
+		dq.EXPECT().Close(mock.Anything).Return(nil)
+		dq.EXPECT().FirmwareInstallSteps(mock.Anything, mock.Anything).Return(nil, errors.New("component not supported"))
+
+		capabilities, err := Capabilities(ctx, dq)
+		require.Nil(t, err)
+
+		for _, c := range capabilities {
+			assert.False(t, c.Supported)
+			assert.Contains(t, c.Reason, "component not supported")
+		}
+	})
+
+	t.Run("open error", func(t *testing.T) {
+		dq := device.NewMockOutofbandQueryor(t)
+		dq.EXPECT().Open(mock.Anything).Return(errors.New("401: unauthorized"))
+
+		_, err := Capabilities(ctx, dq)
+		assert.ErrorIs(t, err, ErrCapabilities)
+	})
+}
@@ -0,0 +1,56 @@
+package redact
+
+import (
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Hook is a logrus hook that redacts the log message and fields with the process wide Redactor.
+type Hook struct{}
+
+// NewHook returns a logrus hook which redacts log entries.
+func NewHook() logrus.Hook {
+	return &Hook{}
+}
+
+// Levels implements the logrus.Hook interface.
+func (h *Hook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire implements the logrus.Hook interface.
+func (h *Hook) Fire(entry *logrus.Entry) error {
+	entry.Message = String(entry.Message)
+
+	for k, v := range entry.Data {
+		switch value := v.(type) {
+		case string:
+			entry.Data[k] = String(value)
+		case error:
+			entry.Data[k] = String(value.Error())
+		case fmt.Stringer:
+			entry.Data[k] = String(value.String())
+		case []string:
+			redacted := make([]string, len(value))
+			for i := range value {
+				redacted[i] = String(value[i])
+			}
+
+			entry.Data[k] = redacted
+		}
+	}
+
+	return nil
+}
+
+// AddHook adds the redaction hook to the logger, unless its already been added.
+func AddHook(logger *logrus.Logger) {
+	for _, hook := range logger.Hooks[logrus.InfoLevel] {
+		if _, ok := hook.(*Hook); ok {
+			return
+		}
+	}
+
+	logger.AddHook(NewHook())
+}
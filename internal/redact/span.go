@@ -0,0 +1,84 @@
+package redact
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// SpanExporter wraps a span exporter to redact span names, attributes, events and status descriptions
+// with the process wide Redactor before the spans are exported.
+type SpanExporter struct {
+	sdktrace.SpanExporter
+}
+
+// NewSpanExporter returns a SpanExporter which redacts spans before they are exported by the given exporter.
+func NewSpanExporter(exporter sdktrace.SpanExporter) sdktrace.SpanExporter {
+	return &SpanExporter{exporter}
+}
+
+// ExportSpans implements the sdktrace.SpanExporter interface.
+func (e *SpanExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	redacted := make([]sdktrace.ReadOnlySpan, 0, len(spans))
+	for _, span := range spans {
+		redacted = append(redacted, &redactedSpan{span})
+	}
+
+	return e.SpanExporter.ExportSpans(ctx, redacted)
+}
+
+// redactedSpan overrides the ReadOnlySpan methods which return values that may include sensitive values.
+type redactedSpan struct {
+	sdktrace.ReadOnlySpan
+}
+
+func (s *redactedSpan) Name() string {
+	return Exported(s.ReadOnlySpan.Name())
+}
+
+func (s *redactedSpan) Attributes() []attribute.KeyValue {
+	return redactAttributes(s.ReadOnlySpan.Attributes())
+}
+
+func (s *redactedSpan) Events() []sdktrace.Event {
+	events := s.ReadOnlySpan.Events()
+	redacted := make([]sdktrace.Event, 0, len(events))
+
+	for _, event := range events {
+		event.Name = Exported(event.Name)
+		event.Attributes = redactAttributes(event.Attributes)
+		redacted = append(redacted, event)
+	}
+
+	return redacted
+}
+
+func (s *redactedSpan) Status() sdktrace.Status {
+	status := s.ReadOnlySpan.Status()
+	status.Description = Exported(status.Description)
+
+	return status
+}
+
+func redactAttributes(attrs []attribute.KeyValue) []attribute.KeyValue {
+	redacted := make([]attribute.KeyValue, 0, len(attrs))
+
+	for _, attr := range attrs {
+		switch attr.Value.Type() {
+		case attribute.STRING:
+			attr = attr.Key.String(Exported(attr.Value.AsString()))
+		case attribute.STRINGSLICE:
+			values := attr.Value.AsStringSlice()
+			for i := range values {
+				values[i] = Exported(values[i])
+			}
+
+			attr = attr.Key.StringSlice(values)
+		}
+
+		redacted = append(redacted, attr)
+	}
+
+	return redacted
+}
@@ -0,0 +1,152 @@
+package runner
+
+import (
+	"context"
+	"testing"
+
+	"github.com/metal-toolbox/flasher/internal/metrics"
+	"github.com/metal-toolbox/flasher/internal/model"
+	rctypes "github.com/metal-toolbox/rivets/v2/condition"
+	rtypes "github.com/metal-toolbox/rivets/v2/types"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	mock "github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// sampleCount returns the count of observations recorded by the summary.
+func sampleCount(t *testing.T, observer prometheus.Observer) uint64 {
+	t.Helper()
+
+	m := &dto.Metric{}
+	require.Nil(t, observer.(prometheus.Metric).Write(m))
+
+	return m.GetSummary().GetSampleCount()
+}
+
+func TestRunTaskMetrics(t *testing.T) {
+	vendor := "metrics-test"
+
+	conditionSucceeded := metrics.ConditionRunTimeSummary.WithLabelValues(string(rctypes.FirmwareInstall), string(model.StateSucceeded))
+	conditionFailed := metrics.ConditionRunTimeSummary.WithLabelValues(string(rctypes.FirmwareInstall), string(model.StateFailed))
+	stepSucceeded := metrics.ActionHandlerRunTimeSummary.WithLabelValues("step1", vendor, "bios", string(model.StateSucceeded))
+	stepFailed := metrics.ActionHandlerRunTimeSummary.WithLabelValues("step2", vendor, "bios", string(model.StateFailed))
+	actionSucceeded := metrics.ActionRuntimeSummary.WithLabelValues(vendor, "bios", string(model.StateSucceeded))
+
+	newTask := func(step2Err error) *model.Task {
+		return &model.Task{
+			Kind:  rctypes.FirmwareInstall,
+			State: model.StatePending,
+			Data: &model.TaskData{
+				ActionsPlanned: []*model.Action{
+					{
+						ID:       "action1",
+						Firmware: rctypes.Firmware{Component: "bios", Vendor: vendor},
+						State:    model.StatePending,
+						Steps: []*model.Step{
+							{
+								Name:  "step1",
+								State: model.StatePending,
+								Handler: func(context.Context) error {
+									// the task and action are in flight while the steps run
+									assert.Equal(t, float64(1), testutil.ToFloat64(metrics.TasksInFlight))
+									assert.Equal(t, float64(1), testutil.ToFloat64(metrics.ActionsInFlight))
+									return nil
+								},
+							},
+							{
+								Name:    "step2",
+								State:   model.StatePending,
+								Handler: func(context.Context) error { return step2Err },
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	newHandler := func(success bool) *MockTaskHandler {
+		m := new(MockTaskHandler)
+		m.On("Initialize", mock.Anything).Return(nil)
+		m.On("Query", mock.Anything).Return(nil)
+		m.On("PlanActions", mock.Anything).Return(nil)
+		m.On("Publish", mock.Anything).Return(nil)
+
+		if success {
+			m.On("OnSuccess", mock.Anything, mock.Anything).Once()
+		} else {
+			m.On("OnFailure", mock.Anything, mock.Anything).Once()
+		}
+
+		return m
+	}
+
+	t.Run("task succeeded", func(t *testing.T) {
+		before := map[string]uint64{
+			"condition": sampleCount(t, conditionSucceeded),
+			"step":      sampleCount(t, stepSucceeded),
+			"action":    sampleCount(t, actionSucceeded),
+		}
+
+		r := New(logrus.NewEntry(logrus.New()))
+		require.Nil(t, r.RunTask(context.Background(), newTask(nil), newHandler(true)))
+
+		assert.Equal(t, before["condition"]+1, sampleCount(t, conditionSucceeded))
+		assert.Equal(t, before["step"]+1, sampleCount(t, stepSucceeded))
+		assert.Equal(t, before["action"]+1, sampleCount(t, actionSucceeded))
+		assert.Equal(t, float64(0), testutil.ToFloat64(metrics.TasksInFlight))
+		assert.Equal(t, float64(0), testutil.ToFloat64(metrics.ActionsInFlight))
+	})
+
+	t.Run("task failed", func(t *testing.T) {
+		before := map[string]uint64{
+			"condition": sampleCount(t, conditionFailed),
+			"step":      sampleCount(t, stepFailed),
+		}
+
+		failures := metrics.TaskFailuresCounter.WithLabelValues(string(model.FailureUnknown), "", "", "bios")
+		failuresBefore := testutil.ToFloat64(failures)
+
+		task := newTask(errors.New("BMC returned 500"))
+
+		r := New(logrus.NewEntry(logrus.New()))
+		require.NotNil(t, r.RunTask(context.Background(), task, newHandler(false)))
+
+		assert.Equal(t, before["condition"]+1, sampleCount(t, conditionFailed))
+		assert.Equal(t, before["step"]+1, sampleCount(t, stepFailed))
+		assert.Equal(t, float64(0), testutil.ToFloat64(metrics.TasksInFlight))
+		assert.Equal(t, float64(0), testutil.ToFloat64(metrics.ActionsInFlight))
+		assert.Equal(t, model.FailureUnknown, task.Data.FailureCode)
+		assert.Equal(t, failuresBefore+1, testutil.ToFloat64(failures))
+
+		// the action, step attempts are recorded with the error
+		action := task.Data.ActionsPlanned[0]
+		require.Len(t, action.History, 1)
+		assert.Contains(t, action.History[0].Error, "BMC returned 500")
+		assert.False(t, action.CompletedAt.IsZero())
+
+		require.Len(t, action.Steps[0].History, 1)
+		assert.Empty(t, action.Steps[0].History[0].Error)
+		require.Len(t, action.Steps[1].History, 1)
+		assert.Equal(t, "BMC returned 500", action.Steps[1].History[0].Error)
+	})
+
+	t.Run("task failure classified", func(t *testing.T) {
+		failures := metrics.TaskFailuresCounter.WithLabelValues(string(model.FailureTimeout), "dell", "r6515", "bios")
+		failuresBefore := testutil.ToFloat64(failures)
+
+		task := newTask(errors.Wrap(context.DeadlineExceeded, "upload"))
+		task.Server = &rtypes.Server{Vendor: "dell", Model: "r6515"}
+
+		r := New(logrus.NewEntry(logrus.New()))
+		require.NotNil(t, r.RunTask(context.Background(), task, newHandler(false)))
+
+		assert.Equal(t, model.FailureTimeout, task.Data.FailureCode)
+		assert.Equal(t, failuresBefore+1, testutil.ToFloat64(failures))
+	})
+}
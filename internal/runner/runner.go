@@ -13,6 +13,8 @@ import (
 	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
 
 	rctypes "github.com/metal-toolbox/rivets/v2/condition"
 )
@@ -86,12 +88,25 @@ func (r *Runner) RunTask(ctx context.Context, task *model.Task, handler TaskHand
 		{"PlanActions", handler.PlanActions},
 	}
 
+	startTS := time.Now()
+
+	metrics.TasksInFlight.Inc()
+	defer metrics.TasksInFlight.Dec()
+
+	ctx, span := startTaskSpan(ctx, task)
+
 	taskFailed := func(err error) error {
 		// no error returned
 		task.SetState(model.StateFailed)
-		task.Status.Append("task failed")
-		task.Status.Append(err.Error())
+		task.AppendStatus("task failed")
+		task.AppendStatus(err.Error())
+		task.Data.FailureCode = model.ClassifyFailure(err)
 		handler.Publish(ctx)
+		registerTaskMetric(startTS, task)
+		registerFailureMetric(task)
+
+		span.SetAttributes(attribute.String("failureCode", string(task.Data.FailureCode)))
+		endSpan(span, model.StateFailed, err)
 
 		handler.OnFailure(ctx, task)
 
@@ -101,8 +116,10 @@ func (r *Runner) RunTask(ctx context.Context, task *model.Task, handler TaskHand
 	taskSuccess := func() error {
 		// no error returned
 		task.SetState(model.StateSucceeded)
-		task.Status.Append("task completed successfully")
+		task.AppendStatus("task completed successfully")
 		handler.Publish(ctx)
+		registerTaskMetric(startTS, task)
+		endSpan(span, model.StateSucceeded, nil)
 
 		handler.OnSuccess(ctx, task)
 
@@ -120,10 +137,15 @@ func (r *Runner) RunTask(ctx context.Context, task *model.Task, handler TaskHand
 
 	// incase this ever happens
 	if rctypes.StateIsComplete(task.State) {
-		task.Status.Append("Task already in final state, nothing to do here")
+		task.AppendStatus("Task already in final state, nothing to do here")
 		return taskSuccess()
 	}
 
+	// the deadline is set once, a resumed task retains the deadline set when it was first run
+	if err := task.SetDeadline(startTS); err != nil {
+		return taskFailed(err)
+	}
+
 	// task was resumed
 	if task.State != model.StateActive {
 		// no error returned
@@ -133,11 +155,19 @@ func (r *Runner) RunTask(ctx context.Context, task *model.Task, handler TaskHand
 
 	// initialize, plan actions
 	for _, f := range funcs {
+		if derr := task.DeadlineExceeded(time.Now(), "", f.name); derr != nil {
+			return taskFailed(derr)
+		}
+
 		if cferr := r.conditionalFault(ctx, f.name, task, handler); cferr != nil {
 			return taskFailed(cferr)
 		}
 
-		if err := f.method(ctx); err != nil {
+		phaseCtx, phaseSpan := otel.Tracer(pkgName).Start(ctx, f.name)
+		err := f.method(phaseCtx)
+		endSpan(phaseSpan, stepState(err), err)
+
+		if err != nil {
 			return taskFailed(err)
 		}
 	}
@@ -159,6 +189,7 @@ func (r *Runner) runActions(ctx context.Context, task *model.Task, handler TaskH
 	}
 
 	finalize := func(state rctypes.State, startTS time.Time, action *model.Action, err error) error {
+		action.AttemptCompleted(time.Now(), err)
 		action.SetState(state)
 		handler.Publish(ctx)
 		registerMetric(startTS, action, state)
@@ -191,33 +222,51 @@ func (r *Runner) runActions(ctx context.Context, task *model.Task, handler TaskH
 		}
 
 		// fetch action attributes from task
+		action.AttemptStarted(time.Now())
 		action.SetState(model.StateActive)
 		handler.Publish(ctx)
 
+		actionCtx, actionSpan := startActionSpan(ctx, action)
+
 		// return
-		runNext, err := r.runActionSteps(ctx, task, action, handler, actionLogger)
+		metrics.ActionsInFlight.Inc()
+		runNext, err := r.runActionSteps(actionCtx, task, action, handler, actionLogger)
+		metrics.ActionsInFlight.Dec()
 		if err != nil {
 			if errors.Is(err, model.ErrHostPowerCycleRequired) {
 				actionLogger.Info("host powercycle required to proceed, exiting")
+				endActionSpan(actionSpan, action, action.State, nil)
 				os.Exit(0)
 			}
 
+			endActionSpan(actionSpan, action, rctypes.Failed, err)
 			return finalize(rctypes.Failed, startTS, action, err)
 		}
 
 		if !runNext {
 			info := "no further actions required"
 			actionLogger.Info(info)
-			task.Status.Append(info)
+			task.AppendStatus(info)
 
+			endActionSpan(actionSpan, action, rctypes.Succeeded, nil)
 			return finalize(rctypes.Succeeded, startTS, action, nil)
 		}
 
 		// log and publish status
+		action.AttemptCompleted(time.Now(), nil)
 		action.SetState(rctypes.Succeeded)
 		handler.Publish(ctx)
 		registerMetric(startTS, action, rctypes.Succeeded)
+		endActionSpan(actionSpan, action, rctypes.Succeeded, nil)
 		actionLogger.Info("action steps for component completed successfully")
+
+		// the steps following the BMC job initiation are run past the deadline, the overrun is reported here
+		if action.BMCTaskID != "" {
+			if derr := task.DeadlineExceeded(time.Now(), action.Firmware.Component, ""); derr != nil {
+				actionLogger.WithError(derr).Warn("task deadline exceeded while the BMC job was running")
+				return derr
+			}
+		}
 	}
 
 	return nil
@@ -282,7 +331,7 @@ func (r *Runner) runActionSteps(ctx context.Context, task *model.Task, action *m
 			method = string(model.RunInband)
 		}
 
-		task.Status.Append(fmt.Sprintf(
+		task.AppendStatus(fmt.Sprintf(
 			"[%s] install %s version: %s, state: %s, step %s",
 			action.Firmware.Component,
 			method,
@@ -309,6 +358,18 @@ func (r *Runner) runActionSteps(ctx context.Context, task *model.Task, action *m
 			continue
 		}
 
+		// the deadline is checked before each step, steps are not interrupted unless marked interruptible.
+		//
+		// Once a BMC job is initiated for the action, the deadline is not checked before the remaining steps
+		// and the steps are not interrupted, so the job is not left running on the BMC with nothing polling for its status,
+		// the deadline overrun is reported once the action completes.
+		if action.BMCTaskID == "" {
+			if derr := task.DeadlineExceeded(time.Now(), action.Firmware.Component, string(step.Name)); derr != nil {
+				publish(model.StateFailed, action, step, logger)
+				return false, derr
+			}
+		}
+
 		publish(model.StateActive, action, step, logger)
 
 		if step.Handler == nil {
@@ -322,10 +383,24 @@ func (r *Runner) runActionSteps(ctx context.Context, task *model.Task, action *m
 		}
 
 		// run step
-		if err := step.Handler(ctx); err != nil {
+		stepStartTS := time.Now()
+		step.AttemptStarted(stepStartTS)
+		stepCtx, stepSpan := startStepSpan(ctx, action, step)
+		err = r.runStep(stepCtx, task, action, step)
+		step.AttemptCompleted(time.Now(), err)
+		registerStepMetric(stepStartTS, action, step, err)
+		endStepSpan(stepSpan, action, step, err)
+
+		if err != nil {
+			// the step was interrupted by the task deadline
+			if derr := task.DeadlineExceeded(time.Now(), action.Firmware.Component, string(step.Name)); derr != nil && interruptible(task, action, step) {
+				publish(model.StateFailed, action, step, logger)
+				return false, errors.Wrap(derr, err.Error())
+			}
+
 			// installed firmware equals expected
 			if errors.Is(err, model.ErrInstalledFirmwareEqual) {
-				task.Status.Append(
+				task.AppendStatus(
 					fmt.Sprintf(
 						"[%s] %s",
 						action.Firmware.Component,
@@ -362,6 +437,24 @@ func (r *Runner) runActionSteps(ctx context.Context, task *model.Task, action *m
 	return true, nil
 }
 
+// runStep runs the step handler, an interruptible step is run with a context that expires at the task deadline.
+func (r *Runner) runStep(ctx context.Context, task *model.Task, action *model.Action, step *model.Step) error {
+	if !interruptible(task, action, step) {
+		return step.Handler(ctx)
+	}
+
+	ctx, cancel := context.WithDeadline(ctx, *task.Data.Deadline)
+	defer cancel()
+
+	return step.Handler(ctx)
+}
+
+// interruptible returns true when the step is to be interrupted at the task deadline,
+// steps are not interrupted once a BMC job is initiated for the action.
+func interruptible(task *model.Task, action *model.Action, step *model.Step) bool {
+	return step.Interruptible && task.Data.Deadline != nil && action.BMCTaskID == ""
+}
+
 // resumeStep returns true when the step can be resumed, when a false is returned with no error, the step is to be skipped.
 func (r *Runner) resumeStep(step *model.Step, logger *logrus.Entry) (resume bool, err error) {
 	errResumeStep := errors.New("error in resuming step")
@@ -427,7 +520,7 @@ func (r *Runner) conditionalFault(ctx context.Context, fname string, task *model
 			return nil
 		}
 
-		task.Status.Append("condition induced delay: " + td.String())
+		task.AppendStatus("condition induced delay: " + td.String())
 		handler.Publish(ctx)
 
 		r.logger.WithField("delay", td.String()).Warn("condition induced delay in execution")
@@ -441,6 +534,61 @@ func (r *Runner) conditionalFault(ctx context.Context, fname string, task *model
 	return nil
 }
 
+func registerTaskMetric(startTS time.Time, task *model.Task) {
+	metrics.ConditionRunTimeSummary.With(
+		prometheus.Labels{
+			"condition": string(task.Kind),
+			"state":     string(task.State),
+		},
+	).Observe(time.Since(startTS).Seconds())
+}
+
+// registerFailureMetric counts the failed task by its failure code,
+// the component is of the action that failed, and is empty when the task failed before running any action.
+func registerFailureMetric(task *model.Task) {
+	var vendor, deviceModel, component string
+	if task.Server != nil {
+		vendor = task.Server.Vendor
+		deviceModel = task.Server.Model
+	}
+
+	for _, action := range task.Data.ActionsPlanned {
+		if action.State == model.StateFailed {
+			component = action.Firmware.Component
+			break
+		}
+	}
+
+	metrics.TaskFailuresCounter.With(
+		prometheus.Labels{
+			"code":      string(task.Data.FailureCode),
+			"vendor":    vendor,
+			"model":     deviceModel,
+			"component": component,
+		},
+	).Inc()
+}
+
+// stepState returns the outcome of a step, or task phase for the error returned.
+func stepState(err error) rctypes.State {
+	if model.IsFailure(err) {
+		return model.StateFailed
+	}
+
+	return model.StateSucceeded
+}
+
+func registerStepMetric(startTS time.Time, action *model.Action, step *model.Step, err error) {
+	metrics.ActionHandlerRunTimeSummary.With(
+		prometheus.Labels{
+			"transition": string(step.Name),
+			"vendor":     action.Firmware.Vendor,
+			"component":  action.Firmware.Component,
+			"state":      string(stepState(err)),
+		},
+	).Observe(time.Since(startTS).Seconds())
+}
+
 func registerActionMetric(startTS time.Time, action *model.Action, state string) {
 	metrics.ActionRuntimeSummary.With(
 		prometheus.Labels{
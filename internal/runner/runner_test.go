@@ -3,6 +3,7 @@ package runner
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/metal-toolbox/flasher/internal/model"
 	rctypes "github.com/metal-toolbox/rivets/v2/condition"
@@ -426,6 +427,152 @@ func TestRunActionSteps(t *testing.T) {
 	}
 }
 
+func TestRunActionStepsDeadline(t *testing.T) {
+	expired := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		deadline time.Time
+		// timeout when set is the deadline relative to when the step is run
+		timeout       time.Duration
+		interruptible bool
+		expectedRun   bool
+		expectedError string
+	}{
+		{
+			name:          "deadline exceeded before step",
+			deadline:      expired,
+			expectedRun:   false,
+			expectedError: "deadline: 2024-01-01T00:00:00Z, component: test, step: step1: task deadline exceeded",
+		},
+		{
+			name:          "interruptible step interrupted at deadline",
+			timeout:       50 * time.Millisecond,
+			interruptible: true,
+			expectedRun:   true,
+			expectedError: "context deadline exceeded",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ran bool
+
+			deadline := tt.deadline
+			if tt.timeout > 0 {
+				deadline = time.Now().Add(tt.timeout)
+			}
+
+			task := &model.Task{Data: &model.TaskData{Deadline: &deadline}}
+			action := &model.Action{
+				Firmware: rctypes.Firmware{Component: "test", Version: "1.0"},
+				Steps: []*model.Step{
+					{
+						Name:          "step1",
+						State:         model.StatePending,
+						Interruptible: tt.interruptible,
+						Handler: func(ctx context.Context) error {
+							ran = true
+							<-ctx.Done()
+							return ctx.Err()
+						},
+					},
+				},
+			}
+
+			mockHandler := new(MockTaskHandler)
+			mockHandler.On("Publish", mock.Anything).Return(nil)
+
+			r := New(logrus.NewEntry(logrus.New()))
+			proceed, err := r.runActionSteps(context.Background(), task, action, mockHandler, r.logger)
+
+			assert.False(t, proceed)
+			assert.Equal(t, tt.expectedRun, ran)
+			assert.Equal(t, model.StateFailed, action.Steps[0].State)
+			assert.ErrorIs(t, err, model.ErrTaskDeadlineExceeded)
+			assert.ErrorContains(t, err, tt.expectedError)
+			assert.Equal(t, model.FailureDeadlineExceeded, model.ClassifyFailure(err))
+		})
+	}
+}
+
+func TestRunActionsDeadlineInstallInitiated(t *testing.T) {
+	deadline := time.Now().Add(20 * time.Millisecond)
+
+	var polled, poweredOff bool
+
+	action := &model.Action{
+		ID:       "action1",
+		Firmware: rctypes.Firmware{Component: "test", Version: "1.0"},
+		State:    model.StatePending,
+	}
+
+	action.Steps = []*model.Step{
+		{
+			Name:  "uploadFirmwareInitiateInstall",
+			State: model.StatePending,
+			Handler: func(context.Context) error {
+				action.BMCTaskID = "JID_1"
+				return nil
+			},
+		},
+		{
+			// the poll is started before the deadline and is not interrupted at the deadline
+			Name:          "pollInstallStatus",
+			State:         model.StatePending,
+			Interruptible: true,
+			Handler: func(ctx context.Context) error {
+				time.Sleep(time.Until(deadline) + 10*time.Millisecond)
+				polled = true
+
+				return ctx.Err()
+			},
+		},
+		{
+			Name:  "powerOffServer",
+			State: model.StatePending,
+			Handler: func(context.Context) error {
+				poweredOff = true
+				return nil
+			},
+		},
+	}
+
+	next := &model.Action{
+		ID:       "action2",
+		Firmware: rctypes.Firmware{Component: "next", Version: "1.0"},
+		State:    model.StatePending,
+		Steps: []*model.Step{
+			{
+				Name:    "checkInstalledFirmware",
+				State:   model.StatePending,
+				Handler: func(context.Context) error { return nil },
+			},
+		},
+	}
+
+	task := &model.Task{Data: &model.TaskData{Deadline: &deadline, ActionsPlanned: model.Actions{action, next}}}
+
+	mockHandler := new(MockTaskHandler)
+	mockHandler.On("Publish", mock.Anything).Return(nil)
+
+	r := New(logrus.NewEntry(logrus.New()))
+	err := r.runActions(context.Background(), task, mockHandler)
+
+	// the BMC job steps are run to completion, the deadline overrun is reported once the action completes
+	assert.True(t, polled)
+	assert.True(t, poweredOff)
+	assert.Equal(t, model.StateSucceeded, action.State)
+
+	for _, step := range action.Steps {
+		assert.Equal(t, model.StateSucceeded, step.State, step.Name)
+	}
+
+	assert.ErrorIs(t, err, model.ErrTaskDeadlineExceeded)
+	assert.EqualError(t, err, "deadline: "+deadline.Format(time.RFC3339)+", component: test: task deadline exceeded")
+	assert.Equal(t, model.StatePending, next.State)
+}
+
 func TestResumeStep(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -0,0 +1,129 @@
+package runner
+
+import (
+	"context"
+
+	"github.com/metal-toolbox/flasher/internal/model"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	rctypes "github.com/metal-toolbox/rivets/v2/condition"
+)
+
+const (
+	pkgName = "internal/runner"
+)
+
+// startTaskSpan starts the task span, linked to the span of the condition the task was created for.
+func startTaskSpan(ctx context.Context, task *model.Task) (context.Context, trace.Span) {
+	attrs := []attribute.KeyValue{
+		attribute.String("conditionID", task.ID.String()),
+		attribute.String("conditionKind", string(task.Kind)),
+	}
+
+	if task.Server != nil {
+		attrs = append(
+			attrs,
+			attribute.String("serverID", task.Server.ID),
+			attribute.String("vendor", task.Server.Vendor),
+			attribute.String("model", task.Server.Model),
+		)
+	}
+
+	opts := []trace.SpanStartOption{trace.WithAttributes(attrs...)}
+
+	if link, ok := conditionLink(task); ok {
+		opts = append(opts, trace.WithLinks(link))
+	}
+
+	return otel.Tracer(pkgName).Start(ctx, "RunTask", opts...)
+}
+
+// conditionLink returns a link to the condition span from the trace, span identifiers carried on the task.
+func conditionLink(task *model.Task) (trace.Link, bool) {
+	traceID, err := trace.TraceIDFromHex(task.TraceID)
+	if err != nil {
+		return trace.Link{}, false
+	}
+
+	spanID, err := trace.SpanIDFromHex(task.SpanID)
+	if err != nil {
+		return trace.Link{}, false
+	}
+
+	sc := trace.NewSpanContext(
+		trace.SpanContextConfig{
+			TraceID:    traceID,
+			SpanID:     spanID,
+			TraceFlags: trace.FlagsSampled,
+			Remote:     true,
+		},
+	)
+
+	return trace.Link{SpanContext: sc}, true
+}
+
+func startActionSpan(ctx context.Context, action *model.Action) (context.Context, trace.Span) {
+	return otel.Tracer(pkgName).Start(
+		ctx,
+		"Action",
+		trace.WithAttributes(
+			attribute.String("actionID", action.ID),
+			attribute.String("component", action.Firmware.Component),
+			attribute.String("vendor", action.Firmware.Vendor),
+			attribute.String("version", action.Firmware.Version),
+			attribute.String("installMethod", string(action.InstallMethod)),
+		),
+	)
+}
+
+func startStepSpan(ctx context.Context, action *model.Action, step *model.Step) (context.Context, trace.Span) {
+	return otel.Tracer(pkgName).Start(
+		ctx,
+		"Step",
+		trace.WithAttributes(
+			attribute.String("step", string(step.Name)),
+			attribute.String("component", action.Firmware.Component),
+			attribute.String("vendor", action.Firmware.Vendor),
+			attribute.String("version", action.Firmware.Version),
+		),
+	)
+}
+
+// endActionSpan records the action attempts, BMC task identifier and outcome before ending the span.
+func endActionSpan(span trace.Span, action *model.Action, state rctypes.State, err error) {
+	span.SetAttributes(
+		attribute.Int("attempts", action.Attempts),
+		attribute.String("bmcTaskID", action.BMCTaskID),
+	)
+
+	endSpan(span, state, err)
+}
+
+// endStepSpan records the step attempts, BMC task identifier and outcome before ending the span.
+func endStepSpan(span trace.Span, action *model.Action, step *model.Step, err error) {
+	span.SetAttributes(
+		attribute.Int("attempts", step.Attempts),
+		attribute.String("bmcTaskID", action.BMCTaskID),
+	)
+
+	endSpan(span, stepState(err), err)
+}
+
+// endSpan records the outcome and ends the span, the span status is set to error when the outcome is failed.
+func endSpan(span trace.Span, state rctypes.State, err error) {
+	span.SetAttributes(attribute.String("outcome", string(state)))
+
+	if state == model.StateFailed {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		} else {
+			span.SetStatus(codes.Error, string(state))
+		}
+	}
+
+	span.End()
+}
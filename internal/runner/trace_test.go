@@ -0,0 +1,142 @@
+package runner
+
+import (
+	"context"
+	"testing"
+
+	"github.com/metal-toolbox/flasher/internal/model"
+	rctypes "github.com/metal-toolbox/rivets/v2/condition"
+	rtypes "github.com/metal-toolbox/rivets/v2/types"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	mock "github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func spanAttributes(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	attrs := map[attribute.Key]attribute.Value{}
+	for _, attr := range span.Attributes() {
+		attrs[attr.Key] = attr.Value
+	}
+
+	return attrs
+}
+
+func TestRunTaskSpans(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	task := &model.Task{
+		Kind:    rctypes.FirmwareInstall,
+		State:   model.StatePending,
+		TraceID: "4bf92f3577b34da6a3ce929d0e0e4736",
+		SpanID:  "00f067aa0ba902b7",
+		Server:  &rtypes.Server{ID: "server-1", Vendor: "dell", Model: "r6515"},
+		Data: &model.TaskData{
+			ActionsPlanned: []*model.Action{
+				{
+					ID:       "action1",
+					Firmware: rctypes.Firmware{Component: "bios", Vendor: "dell", Version: "2.19.6"},
+					State:    model.StatePending,
+					Steps: []*model.Step{
+						{
+							Name:  "uploadFirmware",
+							State: model.StatePending,
+							Handler: func(context.Context) error {
+								return nil
+							},
+						},
+					},
+				},
+				{
+					ID:        "action2",
+					Firmware:  rctypes.Firmware{Component: "bmc", Vendor: "dell", Version: "7.00.00.00"},
+					State:     model.StatePending,
+					BMCTaskID: "JID_1",
+					Steps: []*model.Step{
+						{
+							Name:  "pollInstallStatus",
+							State: model.StatePending,
+							Handler: func(context.Context) error {
+								return errors.New("BMC job failed")
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	handler := new(MockTaskHandler)
+	handler.On("Initialize", mock.Anything).Return(nil)
+	handler.On("Query", mock.Anything).Return(nil)
+	handler.On("PlanActions", mock.Anything).Return(nil)
+	handler.On("Publish", mock.Anything).Return(nil)
+	handler.On("OnFailure", mock.Anything, mock.Anything).Once()
+
+	r := New(logrus.NewEntry(logrus.New()))
+	require.NotNil(t, r.RunTask(context.Background(), task, handler))
+
+	spans := map[string][]sdktrace.ReadOnlySpan{}
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = append(spans[span.Name()], span)
+	}
+
+	require.Len(t, spans["RunTask"], 1)
+	require.Len(t, spans["Action"], 2)
+	require.Len(t, spans["Step"], 2)
+
+	for _, name := range []string{"Initialize", "Query", "PlanActions"} {
+		require.Len(t, spans[name], 1, name)
+	}
+
+	taskSpan := spans["RunTask"][0]
+	assert.Equal(t, codes.Error, taskSpan.Status().Code)
+	assert.Equal(t, "dell", spanAttributes(taskSpan)["vendor"].AsString())
+	assert.Equal(t, string(model.FailureUnknown), spanAttributes(taskSpan)["failureCode"].AsString())
+
+	// the task span is linked to the condition span
+	require.Len(t, taskSpan.Links(), 1)
+	assert.Equal(t, task.TraceID, taskSpan.Links()[0].SpanContext.TraceID().String())
+	assert.Equal(t, task.SpanID, taskSpan.Links()[0].SpanContext.SpanID().String())
+
+	// the task phase, action spans are children of the task span, the step spans are children of the action spans
+	for _, span := range append(spans["Initialize"], spans["Action"]...) {
+		assert.Equal(t, taskSpan.SpanContext().SpanID(), span.Parent().SpanID())
+	}
+
+	actions := map[string]sdktrace.ReadOnlySpan{}
+	for _, span := range spans["Action"] {
+		actions[spanAttributes(span)["component"].AsString()] = span
+	}
+
+	assert.Equal(t, "2.19.6", spanAttributes(actions["bios"])["version"].AsString())
+	assert.Equal(t, string(model.StateSucceeded), spanAttributes(actions["bios"])["outcome"].AsString())
+	assert.Equal(t, string(model.StateFailed), spanAttributes(actions["bmc"])["outcome"].AsString())
+	assert.Equal(t, "JID_1", spanAttributes(actions["bmc"])["bmcTaskID"].AsString())
+	assert.Equal(t, codes.Error, actions["bmc"].Status().Code)
+
+	for _, span := range spans["Step"] {
+		attrs := spanAttributes(span)
+
+		parent := actions[attrs["component"].AsString()]
+		assert.Equal(t, parent.SpanContext().SpanID(), span.Parent().SpanID())
+
+		switch attrs["step"].AsString() {
+		case "uploadFirmware":
+			assert.Equal(t, string(model.StateSucceeded), attrs["outcome"].AsString())
+		case "pollInstallStatus":
+			assert.Equal(t, string(model.StateFailed), attrs["outcome"].AsString())
+			assert.Len(t, span.Events(), 1, "expected the step error to be recorded")
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package secrets
+
+import (
+	"context"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+)
+
+// ClientCredentialsTokenSource returns an oauth2 client credentials token source,
+// the client secret is resolved from the reference each time a token is requested, so a rotated secret is picked up
+// when the current token expires.
+func ClientCredentialsTokenSource(ctx context.Context, cfg *clientcredentials.Config, ref string) oauth2.TokenSource {
+	return oauth2.ReuseTokenSource(nil, &clientCredentialsSource{ctx: ctx, cfg: *cfg, ref: ref})
+}
+
+type clientCredentialsSource struct {
+	ctx context.Context
+	cfg clientcredentials.Config
+	ref string
+}
+
+func (s *clientCredentialsSource) Token() (*oauth2.Token, error) {
+	secret, err := Resolve(s.ctx, s.ref)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := s.cfg
+	cfg.ClientSecret = secret
+
+	return cfg.Token(s.ctx)
+}
@@ -0,0 +1,202 @@
+// Package secrets resolves BMC credentials and OIDC client secrets from secret references.
+//
+// A secret reference identifies where a secret is read from,
+//
+//	file:///run/secrets/bmc-password - the file contents, or when the path is a directory,
+//	                                   the username, password files in the directory (Kubernetes basic-auth secret).
+//	env://BMC_PASSWORD               - the value of the environment variable.
+//	exec:///usr/bin/helper arg1 arg2 - the output of the credential helper.
+//
+// The credential helper command is split on whitespace and is not run in a shell,
+// arguments cannot include spaces or quotes - a wrapper script is to be used for those.
+//
+// References are resolved each time they are looked up, so that rotated secrets,
+// like Kubernetes-mounted secret files which are updated in place, are picked up without a restart.
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	SchemeFile = "file"
+	SchemeEnv  = "env"
+	SchemeExec = "exec"
+
+	// execTimeout is the maximum time a credential helper is allowed to run.
+	execTimeout = 30 * time.Second
+)
+
+var (
+	ErrSecretRef     = errors.New("invalid secret reference")
+	ErrSecretResolve = errors.New("error resolving secret")
+	ErrSecretEmpty   = errors.New("secret resolved to an empty value")
+)
+
+// Credential is a username, password pair.
+type Credential struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// Resolve returns the secret identified by the reference.
+func Resolve(ctx context.Context, ref string) (string, error) {
+	scheme, location, err := parseRef(ref)
+	if err != nil {
+		return "", err
+	}
+
+	var secret string
+
+	switch scheme {
+	case SchemeFile:
+		secret, err = readFile(location)
+	case SchemeEnv:
+		secret, err = readEnv(location)
+	case SchemeExec:
+		secret, err = runHelper(ctx, location)
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	secret = strings.TrimSpace(secret)
+	if secret == "" {
+		return "", errors.Wrap(ErrSecretEmpty, scheme+" reference")
+	}
+
+	return secret, nil
+}
+
+// ResolveCredential returns the credential identified by the reference.
+//
+// A file reference to a directory is read as a Kubernetes basic-auth secret, with the username, password files,
+// for all other references a JSON object with the username, password fields is returned as is,
+// or else the secret is returned as the password.
+func ResolveCredential(ctx context.Context, ref string) (*Credential, error) {
+	scheme, location, err := parseRef(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	if scheme == SchemeFile {
+		info, err := os.Stat(location)
+		if err != nil {
+			return nil, errors.Wrap(ErrSecretResolve, err.Error())
+		}
+
+		if info.IsDir() {
+			return readCredentialDir(location)
+		}
+	}
+
+	secret, err := Resolve(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.HasPrefix(secret, "{") {
+		cred := &Credential{}
+		if err := json.Unmarshal([]byte(secret), cred); err != nil {
+			return nil, errors.Wrap(ErrSecretResolve, "credential JSON: "+err.Error())
+		}
+
+		if cred.Password == "" {
+			return nil, errors.Wrap(ErrSecretEmpty, "credential password")
+		}
+
+		return cred, nil
+	}
+
+	return &Credential{Password: secret}, nil
+}
+
+func parseRef(ref string) (scheme, location string, err error) {
+	scheme, location, found := strings.Cut(ref, "://")
+	if !found || location == "" {
+		return "", "", errors.Wrap(ErrSecretRef, "expected <scheme>://<location>, one of file://, env://, exec://")
+	}
+
+	switch scheme {
+	case SchemeFile, SchemeEnv, SchemeExec:
+		return scheme, location, nil
+	default:
+		return "", "", errors.Wrap(ErrSecretRef, "unsupported scheme: "+scheme)
+	}
+}
+
+func readFile(path string) (string, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", errors.Wrap(ErrSecretResolve, err.Error())
+	}
+
+	return string(b), nil
+}
+
+func readEnv(name string) (string, error) {
+	value, exists := os.LookupEnv(name)
+	if !exists {
+		return "", errors.Wrap(ErrSecretResolve, "env var not set: "+name)
+	}
+
+	return value, nil
+}
+
+func readCredentialDir(dir string) (*Credential, error) {
+	username, err := readFile(filepath.Join(dir, "username"))
+	if err != nil {
+		return nil, err
+	}
+
+	password, err := readFile(filepath.Join(dir, "password"))
+	if err != nil {
+		return nil, err
+	}
+
+	cred := &Credential{
+		Username: strings.TrimSpace(username),
+		Password: strings.TrimSpace(password),
+	}
+
+	if cred.Password == "" {
+		return nil, errors.Wrap(ErrSecretEmpty, "credential password")
+	}
+
+	return cred, nil
+}
+
+// runHelper runs the credential helper command, the arguments are split on whitespace.
+func runHelper(ctx context.Context, command string) (string, error) {
+	args := strings.Fields(command)
+	if len(args) == 0 {
+		return "", errors.Wrap(ErrSecretRef, "exec reference missing command")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, execTimeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+
+	// nolint:gosec // the credential helper command is set by the operator.
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		// the helper stdout is not included since it may contain the secret
+		return "", errors.Wrap(ErrSecretResolve, "credential helper "+args[0]+": "+err.Error()+": "+strings.TrimSpace(stderr.String()))
+	}
+
+	return stdout.String(), nil
+}
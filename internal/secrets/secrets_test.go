@@ -0,0 +1,102 @@
+package secrets
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolve(t *testing.T) {
+	dir := t.TempDir()
+
+	secretFile := filepath.Join(dir, "secret")
+	require.Nil(t, os.WriteFile(secretFile, []byte("hunter2\n"), 0o600))
+
+	t.Setenv("FLASHER_TEST_SECRET", "s3cret")
+
+	testcases := []struct {
+		name    string
+		ref     string
+		want    string
+		wantErr error
+	}{
+		{"file", "file://" + secretFile, "hunter2", nil},
+		{"file does not exist", "file://" + filepath.Join(dir, "nope"), "", ErrSecretResolve},
+		{"env", "env://FLASHER_TEST_SECRET", "s3cret", nil},
+		{"env not set", "env://FLASHER_TEST_SECRET_UNSET", "", ErrSecretResolve},
+		{"exec", "exec:///bin/echo helper-secret", "helper-secret", nil},
+		{"exec fails", "exec:///bin/false", "", ErrSecretResolve},
+		{"exec empty output", "exec:///bin/true", "", ErrSecretEmpty},
+		{"exec arguments split on whitespace", "exec:///bin/echo \"helper   secret\"", "\"helper secret\"", nil},
+		{"plaintext", "hunter2", "", ErrSecretRef},
+		{"unsupported scheme", "vault://secret/bmc", "", ErrSecretRef},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Resolve(context.Background(), tc.ref)
+			if tc.wantErr != nil {
+				assert.ErrorIs(t, err, tc.wantErr)
+				return
+			}
+
+			assert.Nil(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestResolveRotatedFile(t *testing.T) {
+	secretFile := filepath.Join(t.TempDir(), "secret")
+	require.Nil(t, os.WriteFile(secretFile, []byte("before"), 0o600))
+
+	got, err := Resolve(context.Background(), "file://"+secretFile)
+	require.Nil(t, err)
+	assert.Equal(t, "before", got)
+
+	require.Nil(t, os.WriteFile(secretFile, []byte("after"), 0o600))
+
+	got, err = Resolve(context.Background(), "file://"+secretFile)
+	require.Nil(t, err)
+	assert.Equal(t, "after", got)
+}
+
+func TestResolveCredential(t *testing.T) {
+	// Kubernetes basic-auth secret mounted as a directory
+	secretDir := t.TempDir()
+	require.Nil(t, os.WriteFile(filepath.Join(secretDir, "username"), []byte("root"), 0o600))
+	require.Nil(t, os.WriteFile(filepath.Join(secretDir, "password"), []byte("calvin\n"), 0o600))
+
+	t.Setenv("FLASHER_TEST_CREDENTIAL", `{"username": "admin", "password": "hunter2"}`)
+	t.Setenv("FLASHER_TEST_PASSWORD", "hunter2")
+	t.Setenv("FLASHER_TEST_CREDENTIAL_INVALID", `{"username": "admin"`)
+
+	testcases := []struct {
+		name    string
+		ref     string
+		want    *Credential
+		wantErr error
+	}{
+		{"secret directory", "file://" + secretDir, &Credential{Username: "root", Password: "calvin"}, nil},
+		{"json credential", "env://FLASHER_TEST_CREDENTIAL", &Credential{Username: "admin", Password: "hunter2"}, nil},
+		{"password only", "env://FLASHER_TEST_PASSWORD", &Credential{Password: "hunter2"}, nil},
+		{"invalid json", "env://FLASHER_TEST_CREDENTIAL_INVALID", nil, ErrSecretResolve},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ResolveCredential(context.Background(), tc.ref)
+			if tc.wantErr != nil {
+				assert.ErrorIs(t, err, tc.wantErr)
+				return
+			}
+
+			assert.Nil(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
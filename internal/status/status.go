@@ -0,0 +1,282 @@
+// Package status reads the firmware install condition status and task as published by the worker
+// to the NATS KV buckets, and renders it for the CLI.
+package status
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/metal-toolbox/flasher/internal/model"
+	"github.com/metal-toolbox/flasher/types"
+	rctypes "github.com/metal-toolbox/rivets/v2/condition"
+	"github.com/nats-io/nats.go"
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrStatus         = errors.New("error querying condition status")
+	ErrStatusNotFound = errors.New("condition status not found")
+)
+
+// Condition is the status of a firmware install condition.
+type Condition struct {
+	ID string
+	// Value is the condition status value as published to the status KV bucket.
+	Value *types.StatusValue
+	// Record is the status record decoded from the status value.
+	Record *rctypes.StatusRecord
+	// Task is the condition task as published to the task KV bucket,
+	// this is nil when the task was not found.
+	Task *model.Task
+	// Progress is the install progress published with the task data,
+	// this is nil when the task was not found.
+	Progress *model.Progress
+}
+
+// Queryor reads the condition status for the facility from the worker NATS KV buckets.
+type Queryor struct {
+	facilityCode string
+	statusKV     nats.KeyValue
+	taskKV       nats.KeyValue
+}
+
+// NewQueryor returns a Queryor bound to the firmware install status and task KV buckets.
+func NewQueryor(js nats.JetStreamContext, facilityCode string) (*Queryor, error) {
+	statusKV, err := js.KeyValue(string(rctypes.FirmwareInstall))
+	if err != nil {
+		return nil, errors.Wrap(ErrStatus, "status KV bucket: "+err.Error())
+	}
+
+	taskKV, err := js.KeyValue(rctypes.TaskKVRepositoryBucket)
+	if err != nil {
+		return nil, errors.Wrap(ErrStatus, "task KV bucket: "+err.Error())
+	}
+
+	return &Queryor{facilityCode: facilityCode, statusKV: statusKV, taskKV: taskKV}, nil
+}
+
+// Get returns the current condition status.
+func (q *Queryor) Get(conditionID string) (*Condition, error) {
+	entry, err := q.statusKV.Get(rctypes.StatusValueKVKey(q.facilityCode, conditionID))
+	if err != nil {
+		if errors.Is(err, nats.ErrKeyNotFound) {
+			return nil, errors.Wrap(ErrStatusNotFound, conditionID)
+		}
+
+		return nil, errors.Wrap(ErrStatus, err.Error())
+	}
+
+	return q.condition(conditionID, entry.Value())
+}
+
+// Watch invokes fn with the condition status on each update,
+// until the condition is complete, fn returns an error or the context is canceled.
+func (q *Queryor) Watch(ctx context.Context, conditionID string, fn func(*Condition) error) error {
+	watcher, err := q.statusKV.Watch(rctypes.StatusValueKVKey(q.facilityCode, conditionID), nats.Context(ctx))
+	if err != nil {
+		return errors.Wrap(ErrStatus, err.Error())
+	}
+
+	// nolint:errcheck // the watcher is no longer required
+	defer watcher.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case entry, ok := <-watcher.Updates():
+			if !ok {
+				return nil
+			}
+
+			// a nil entry indicates the initial values have been received
+			if entry == nil || entry.Operation() != nats.KeyValuePut {
+				continue
+			}
+
+			condition, err := q.condition(conditionID, entry.Value())
+			if err != nil {
+				return err
+			}
+
+			if err := fn(condition); err != nil {
+				return err
+			}
+
+			if rctypes.StateIsComplete(rctypes.State(condition.Value.State)) {
+				return nil
+			}
+		}
+	}
+}
+
+func (q *Queryor) condition(conditionID string, value []byte) (*Condition, error) {
+	sv := &types.StatusValue{}
+	if err := json.Unmarshal(value, sv); err != nil {
+		return nil, errors.Wrap(ErrStatus, "status value: "+err.Error())
+	}
+
+	condition := &Condition{ID: conditionID, Value: sv, Record: &rctypes.StatusRecord{}}
+
+	if len(sv.Status) > 0 {
+		if err := json.Unmarshal(sv.Status, condition.Record); err != nil {
+			return nil, errors.Wrap(ErrStatus, "status record: "+err.Error())
+		}
+	}
+
+	// the task is published with the target server identifier
+	if sv.Target == "" {
+		return condition, nil
+	}
+
+	key := rctypes.TaskKVRepositoryKey(q.facilityCode, rctypes.FirmwareInstall, sv.Target)
+
+	entry, err := q.taskKV.Get(key)
+	if err != nil {
+		if errors.Is(err, nats.ErrKeyNotFound) {
+			return condition, nil
+		}
+
+		return nil, errors.Wrap(ErrStatus, err.Error())
+	}
+
+	task := &model.Task{}
+	if err := json.Unmarshal(entry.Value(), task); err != nil {
+		return nil, errors.Wrap(ErrStatus, "task: "+err.Error())
+	}
+
+	// the task KV holds the last task for the server, which may be for another condition
+	if task.ID.String() != conditionID {
+		return condition, nil
+	}
+
+	condition.Task = task
+
+	// the progress is not listed when published with a schema version this reader does not support
+	if task.Data != nil && task.Data.Progress != nil && task.Data.Progress.SchemaVersion <= model.ProgressSchemaVersion {
+		condition.Progress = task.Data.Progress
+	}
+
+	return condition, nil
+}
+
+// Write renders the condition status, the task actions, steps and the status log.
+func Write(w io.Writer, c *Condition) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(tw, "condition:\t%s\n", c.ID)
+	fmt.Fprintf(tw, "target:\t%s\n", c.Value.Target)
+	fmt.Fprintf(tw, "worker:\t%s\n", c.Value.WorkerID)
+	fmt.Fprintf(tw, "state:\t%s\n", c.Value.State)
+	fmt.Fprintf(tw, "updated:\t%s\n", c.Value.UpdatedAt.Format(time.RFC3339))
+
+	if c.Task != nil && c.Task.Data != nil && c.Task.Data.FailureCode != "" {
+		fmt.Fprintf(tw, "failure:\t%s\n", c.Task.Data.FailureCode)
+	}
+
+	if c.Progress != nil {
+		fmt.Fprintf(tw, "progress:\t%s\n", progress(c.Progress))
+	}
+
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if c.Task != nil && c.Task.Data != nil && len(c.Task.Data.ActionsPlanned) > 0 {
+		fmt.Fprintln(w, "\nactions:")
+
+		tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "  ACTION\tCOMPONENT\tVERSION\tSTATE\tSTEP\tATTEMPTS\tELAPSED\tSTATUS")
+
+		for _, action := range c.Task.Data.ActionsPlanned {
+			fmt.Fprintf(
+				tw,
+				"  %s\t%s\t%s\t%s\t\t\t%s\t\n",
+				action.ID,
+				action.Firmware.Component,
+				action.Firmware.Version,
+				action.State,
+				action.Elapsed(),
+			)
+
+			for _, step := range action.Steps {
+				fmt.Fprintf(
+					tw,
+					"  \t\t\t%s\t%s\t%d\t%s\t%s\n",
+					step.State,
+					step.Name,
+					step.Attempts,
+					step.Elapsed(),
+					oneLine(step.Status),
+				)
+			}
+		}
+
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+
+	if len(c.Record.StatusMsgs) > 0 {
+		fmt.Fprintln(w, "\nstatus:")
+
+		for _, msg := range c.Record.StatusMsgs {
+			fmt.Fprintf(w, "  %s  %s\n", msg.Timestamp.Format(time.RFC3339), oneLine(msg.Msg))
+		}
+	}
+
+	return nil
+}
+
+// WriteLog renders the task log published with the final task state.
+func WriteLog(w io.Writer, c *Condition) error {
+	if c.Task == nil || c.Task.Data == nil || c.Task.Data.Log == "" {
+		return nil
+	}
+
+	if _, err := fmt.Fprintln(w, "\nlog:"); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, c.Task.Data.Log)
+
+	return err
+}
+
+// progress returns the install progress as a single line.
+func progress(p *model.Progress) string {
+	parts := []string{
+		fmt.Sprintf("action %d/%d %s %s", p.ActionIndex, p.ActionTotal, p.Component, p.Version),
+	}
+
+	if p.Step != "" {
+		parts = append(parts, "step: "+p.Step)
+	}
+
+	if p.BytesDownloaded > 0 {
+		parts = append(parts, fmt.Sprintf("downloaded: %d bytes", p.BytesDownloaded))
+	}
+
+	if p.BytesUploaded > 0 {
+		parts = append(parts, fmt.Sprintf("uploaded: %d bytes", p.BytesUploaded))
+	}
+
+	if p.BMCTaskID != "" {
+		parts = append(parts, fmt.Sprintf("bmc job: %s %d%%", p.BMCTaskID, p.BMCTaskPercent))
+	}
+
+	if p.ETA != nil {
+		parts = append(parts, "eta: "+p.ETA.Format(time.RFC3339))
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
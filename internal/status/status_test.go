@@ -0,0 +1,257 @@
+package status
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/metal-toolbox/ctrl"
+	"github.com/metal-toolbox/flasher/internal/model"
+	"github.com/metal-toolbox/flasher/types"
+	rctypes "github.com/metal-toolbox/rivets/v2/condition"
+	"github.com/metal-toolbox/rivets/v2/events"
+	"github.com/metal-toolbox/rivets/v2/events/registry"
+	rtypes "github.com/metal-toolbox/rivets/v2/types"
+	"github.com/nats-io/nats-server/v2/server"
+	srvtest "github.com/nats-io/nats-server/v2/test"
+	"github.com/nats-io/nats.go"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const facilityCode = "area13"
+
+func runNATSServer(t *testing.T) *server.Server {
+	t.Helper()
+
+	opts := srvtest.DefaultTestOptions
+	opts.Port = -1
+	opts.JetStream = true
+	opts.StoreDir = t.TempDir()
+
+	s := srvtest.RunServer(&opts)
+
+	t.Cleanup(func() {
+		s.Shutdown()
+		s.WaitForShutdown()
+	})
+
+	return s
+}
+
+// fixture publishes the condition status value and task as the worker does.
+type fixture struct {
+	statusKV nats.KeyValue
+	taskKV   nats.KeyValue
+	task     *model.Task
+}
+
+func newFixture(t *testing.T, js nats.JetStreamContext) *fixture {
+	t.Helper()
+
+	statusKV, err := js.CreateKeyValue(&nats.KeyValueConfig{Bucket: string(rctypes.FirmwareInstall)})
+	require.Nil(t, err)
+
+	taskKV, err := js.CreateKeyValue(&nats.KeyValueConfig{Bucket: rctypes.TaskKVRepositoryBucket})
+	require.Nil(t, err)
+
+	task, err := model.NewTask(uuid.New(), rctypes.FirmwareInstall, &rctypes.FirmwareInstallTaskParameters{
+		Firmwares: []rctypes.Firmware{{Component: "bios", Version: "2.19.6"}},
+	})
+	require.Nil(t, err)
+
+	task.Server = &rtypes.Server{ID: uuid.New().String()}
+	task.State = model.StateActive
+	task.Data.ActionsPlanned = model.Actions{
+		{
+			ID:       "bios-1",
+			State:    model.StateActive,
+			Firmware: rctypes.Firmware{Component: "bios", Version: "2.19.6"},
+			Steps: model.Steps{
+				{
+					Name:     "downloadFirmware",
+					State:    model.StateSucceeded,
+					Attempts: 1,
+					Timing: model.Timing{
+						StartedAt:   time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+						CompletedAt: time.Date(2024, 1, 1, 0, 1, 30, 0, time.UTC),
+					},
+				},
+				{Name: "uploadFirmwareInitiateInstall", State: model.StateActive, Attempts: 2, Status: "BMC returned 500"},
+			},
+		},
+	}
+
+	task.Status.Append("installing bios")
+
+	return &fixture{statusKV: statusKV, taskKV: taskKV, task: &task}
+}
+
+func (f *fixture) publish() error {
+	b, err := json.Marshal(f.task)
+	if err != nil {
+		return err
+	}
+
+	if _, err := f.taskKV.Put(rctypes.TaskKVRepositoryKey(facilityCode, rctypes.FirmwareInstall, f.task.Server.ID), b); err != nil {
+		return err
+	}
+
+	sv := &types.StatusValue{
+		UpdatedAt: time.Now(),
+		WorkerID:  "flasher-worker",
+		Target:    f.task.Server.ID,
+		State:     string(f.task.State),
+		Status:    f.task.Status.MustMarshal(),
+	}
+
+	_, err = f.statusKV.Put(rctypes.StatusValueKVKey(facilityCode, f.task.ID.String()), sv.MustBytes())
+
+	return err
+}
+
+func TestQueryor(t *testing.T) {
+	ns := runNATSServer(t)
+
+	nc, err := nats.Connect(ns.ClientURL())
+	require.Nil(t, err)
+	defer nc.Close()
+
+	js, err := nc.JetStream()
+	require.Nil(t, err)
+
+	f := newFixture(t, js)
+	require.Nil(t, f.publish())
+
+	q, err := NewQueryor(js, facilityCode)
+	require.Nil(t, err)
+
+	t.Run("get", func(t *testing.T) {
+		condition, err := q.Get(f.task.ID.String())
+		require.Nil(t, err)
+
+		assert.Equal(t, string(model.StateActive), condition.Value.State)
+		assert.Equal(t, "installing bios", condition.Record.Last())
+		require.NotNil(t, condition.Task)
+		require.Len(t, condition.Task.Data.ActionsPlanned, 1)
+		assert.Equal(t, 2, condition.Task.Data.ActionsPlanned[0].Steps[1].Attempts)
+
+		buf := &bytes.Buffer{}
+		require.Nil(t, Write(buf, condition))
+
+		assert.Regexp(t, `state:\s+active`, buf.String())
+
+		for _, want := range []string{
+			"bios-1",
+			"uploadFirmwareInitiateInstall",
+			"1m30s",
+			"BMC returned 500",
+			"installing bios",
+		} {
+			assert.Contains(t, buf.String(), want)
+		}
+	})
+
+	t.Run("progress", func(t *testing.T) {
+		// the task is published through the worker task status publisher,
+		// the progress is read from the task data.
+		task, err := model.NewTask(uuid.New(), rctypes.FirmwareInstall, f.task.Parameters)
+		require.Nil(t, err)
+
+		task.Server = &rtypes.Server{ID: uuid.New().String()}
+		task.State = model.StateActive
+		task.Data.ActionsPlanned = model.Actions{
+			{
+				ID:             "bios-1",
+				State:          model.StateActive,
+				Firmware:       rctypes.Firmware{Component: "bios", Version: "2.19.6"},
+				BMCTaskID:      "JID_1",
+				BMCTaskPercent: 45,
+				Steps: model.Steps{
+					{Name: "pollInstallStatus", State: model.StateActive, Timing: model.Timing{StartedAt: time.Now()}},
+				},
+			},
+		}
+
+		cp, err := ctrl.NewNatsPublisher(
+			"flasher",
+			task.ID.String(),
+			task.Server.ID,
+			facilityCode,
+			rctypes.FirmwareInstall,
+			registry.GetID("flasher"),
+			1,
+			events.NewJetstreamFromConn(nc),
+			logrus.New(),
+		)
+		require.Nil(t, err)
+
+		publisher := model.NewTaskStatusPublisher(logrus.NewEntry(logrus.New()), cp)
+		require.Nil(t, publisher.Publish(context.Background(), &task))
+
+		condition, err := q.Get(task.ID.String())
+		require.Nil(t, err)
+		require.NotNil(t, condition.Task)
+		require.NotNil(t, condition.Progress)
+		assert.Equal(t, model.ProgressSchemaVersion, condition.Progress.SchemaVersion)
+		assert.Equal(t, 1, condition.Progress.ActionTotal)
+		assert.Equal(t, "pollInstallStatus", condition.Progress.Step)
+		assert.Equal(t, 45, condition.Progress.BMCTaskPercent)
+		assert.NotNil(t, condition.Progress.ETA)
+
+		buf := &bytes.Buffer{}
+		require.Nil(t, Write(buf, condition))
+		assert.Contains(t, buf.String(), "bmc job: JID_1 45%")
+	})
+
+	t.Run("log", func(t *testing.T) {
+		f.task.Data.Log = "level=info msg=\"polling BMC for firmware task status\"\n"
+		defer func() { f.task.Data.Log = "" }()
+
+		require.Nil(t, f.publish())
+
+		condition, err := q.Get(f.task.ID.String())
+		require.Nil(t, err)
+
+		buf := &bytes.Buffer{}
+		require.Nil(t, WriteLog(buf, condition))
+		assert.Contains(t, buf.String(), "polling BMC for firmware task status")
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		_, err := q.Get(uuid.NewString())
+		assert.ErrorIs(t, err, ErrStatusNotFound)
+	})
+
+	t.Run("watch until complete", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		var states []string
+
+		errCh := make(chan error)
+		go func() {
+			errCh <- q.Watch(ctx, f.task.ID.String(), func(c *Condition) error {
+				states = append(states, c.Value.State)
+
+				// complete the condition once the initial status is received
+				if c.Value.State == string(model.StateActive) {
+					f.task.State = model.StateSucceeded
+					f.task.Data.ActionsPlanned[0].State = model.StateSucceeded
+					f.task.Status.Append("installed firmware")
+
+					return f.publish()
+				}
+
+				return nil
+			})
+		}()
+
+		require.Nil(t, <-errCh)
+		assert.Equal(t, []string{string(model.StateActive), string(model.StateSucceeded)}, states)
+	})
+}
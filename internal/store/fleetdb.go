@@ -13,6 +13,7 @@ import (
 
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 	"go.opentelemetry.io/otel"
+	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/clientcredentials"
 
 	"github.com/coreos/go-oidc/v3/oidc"
@@ -23,6 +24,8 @@ import (
 
 	"github.com/metal-toolbox/flasher/internal/app"
 	"github.com/metal-toolbox/flasher/internal/metrics"
+	"github.com/metal-toolbox/flasher/internal/model"
+	"github.com/metal-toolbox/flasher/internal/secrets"
 	"github.com/pkg/errors"
 )
 
@@ -61,6 +64,10 @@ var (
 	ErrFirmwareSetLookup = errors.New("firmware set error")
 )
 
+func init() {
+	model.RegisterFailureCode(model.FailureStoreQuery, ErrServerserviceQuery, ErrFirmwareSetLookup)
+}
+
 var firmwareSetAttributeNS = "sh.hollow.firmware_set.labels"
 
 type FleetDBAPI struct {
@@ -132,14 +139,19 @@ func newClientWithOAuth(ctx context.Context, cfg *app.FleetDBAPIOptions, logger
 	// wrap OAuth transport, cookie jar in the retryable client
 	oAuthclient := oauthConfig.Client(ctx)
 
+	// the client secret is resolved from the secret reference on each token request
+	if cfg.OidcClientSecretRef != "" {
+		oAuthclient = oauth2.NewClient(ctx, secrets.ClientCredentialsTokenSource(ctx, &oauthConfig, cfg.OidcClientSecretRef))
+	}
+
 	retryableClient.HTTPClient.Transport = oAuthclient.Transport
 	retryableClient.HTTPClient.Jar = oAuthclient.Jar
 
 	httpClient := retryableClient.StandardClient()
 	httpClient.Timeout = connectionTimeout
 
-	return fleetdbapi.NewClientWithToken(
-		cfg.OidcClientSecret,
+	// the request Authorization header is set by the OAuth transport with the token
+	return fleetdbapi.NewClient(
 		cfg.Endpoint,
 		httpClient,
 	)
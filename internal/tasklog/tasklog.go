@@ -0,0 +1,195 @@
+// Package tasklog captures the log lines of a task into a size capped buffer,
+// the captured log is published with the final task state and written to a per condition file.
+package tasklog
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/metal-toolbox/flasher/internal/model"
+	"github.com/metal-toolbox/flasher/internal/redact"
+	rctypes "github.com/metal-toolbox/rivets/v2/condition"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	ErrParams = errors.New("invalid task log parameters")
+
+	// params are the parameters applied to each task log,
+	// this is set to the defaults and can be overridden with SetParams.
+	params   = DefaultParams()
+	paramsMu sync.RWMutex
+)
+
+// Params defines the task log capture parameters.
+type Params struct {
+	// MaxBytes caps the size of the captured log, the earliest lines are dropped once exceeded.
+	MaxBytes int
+
+	// Dir when set, the task log is written to a <condition ID>.log file in this directory once the task returns.
+	Dir string
+
+	// DisablePublish disables publishing the task log with the final task state.
+	DisablePublish bool
+}
+
+// DefaultParams returns the default task log parameters,
+// the last 64KiB of the task log is captured and published with the final task state.
+func DefaultParams() Params {
+	return Params{MaxBytes: 64 << 10}
+}
+
+// SetParams overrides the task log parameters, a zero MaxBytes is replaced with the default value.
+func SetParams(p Params) error {
+	if p.MaxBytes == 0 {
+		p.MaxBytes = DefaultParams().MaxBytes
+	}
+
+	if p.MaxBytes < 0 {
+		return errors.Wrap(ErrParams, "max bytes is expected to be a positive value")
+	}
+
+	if p.Dir != "" {
+		info, err := os.Stat(p.Dir)
+		if err != nil {
+			return errors.Wrap(ErrParams, err.Error())
+		}
+
+		if !info.IsDir() {
+			return errors.Wrap(ErrParams, "not a directory: "+p.Dir)
+		}
+	}
+
+	paramsMu.Lock()
+	defer paramsMu.Unlock()
+
+	params = p
+
+	return nil
+}
+
+func currentParams() Params {
+	paramsMu.RLock()
+	defer paramsMu.RUnlock()
+
+	return params
+}
+
+// Buffer holds the log lines of a task, upto the max bytes.
+type Buffer struct {
+	mu      sync.Mutex
+	params  Params
+	lines   [][]byte
+	size    int
+	dropped int
+}
+
+// New returns a Buffer with the current task log parameters.
+func New() *Buffer {
+	return &Buffer{params: currentParams()}
+}
+
+// Write appends the log line to the buffer, dropping the earliest lines when the buffer exceeds its max size.
+func (b *Buffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	line := bytes.Clone(p)
+	if len(line) > b.params.MaxBytes {
+		line = line[len(line)-b.params.MaxBytes:]
+	}
+
+	b.lines = append(b.lines, line)
+	b.size += len(line)
+
+	for b.size > b.params.MaxBytes && len(b.lines) > 0 {
+		b.size -= len(b.lines[0])
+		b.lines = b.lines[1:]
+		b.dropped++
+	}
+
+	return len(p), nil
+}
+
+// String returns the captured log, prefixed with the count of dropped lines if any.
+func (b *Buffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var s bytes.Buffer
+	if b.dropped > 0 {
+		fmt.Fprintf(&s, "... %d log lines dropped ...\n", b.dropped)
+	}
+
+	for _, line := range b.lines {
+		s.Write(line)
+	}
+
+	return s.String()
+}
+
+// WriteFile writes the captured log to the <condition ID>.log file in the configured directory,
+// this is a no-op when the directory is not configured.
+func (b *Buffer) WriteFile(conditionID string) error {
+	if b.params.Dir == "" {
+		return nil
+	}
+
+	path := filepath.Join(b.params.Dir, conditionID+".log")
+
+	return os.WriteFile(path, []byte(redact.String(b.String())), 0o600)
+}
+
+// Hook is a logrus hook that captures formatted log entries into the task log buffer.
+type Hook struct {
+	buffer    *Buffer
+	formatter logrus.Formatter
+}
+
+// AddHook adds a hook to the logger to capture its log entries into the buffer,
+// the hook is to be added after the redaction hook so the captured entries are redacted.
+func AddHook(logger *logrus.Logger, buffer *Buffer) {
+	logger.AddHook(&Hook{buffer: buffer, formatter: logger.Formatter})
+}
+
+// Levels implements the logrus.Hook interface.
+func (h *Hook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire implements the logrus.Hook interface.
+func (h *Hook) Fire(entry *logrus.Entry) error {
+	line, err := h.formatter.Format(entry)
+	if err != nil {
+		return err
+	}
+
+	_, err = h.buffer.Write(line)
+
+	return err
+}
+
+// Publisher decorates a model.Publisher to publish the task log with the final task state.
+type Publisher struct {
+	next   model.Publisher
+	buffer *Buffer
+}
+
+// NewPublisher returns a Publisher that includes the captured task log in the final task state.
+func NewPublisher(next model.Publisher, buffer *Buffer) model.Publisher {
+	return &Publisher{next: next, buffer: buffer}
+}
+
+// Publish implements the model.Publisher interface.
+func (p *Publisher) Publish(ctx context.Context, task *model.Task) error {
+	if !p.buffer.params.DisablePublish && task.Data != nil && rctypes.StateIsComplete(task.State) {
+		task.Data.Log = redact.Exported(p.buffer.String())
+	}
+
+	return p.next.Publish(ctx, task)
+}
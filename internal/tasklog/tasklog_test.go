@@ -0,0 +1,95 @@
+package tasklog
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/metal-toolbox/flasher/internal/model"
+	"github.com/metal-toolbox/flasher/internal/redact"
+	rctypes "github.com/metal-toolbox/rivets/v2/condition"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakePublisher struct {
+	published []string
+}
+
+func (f *fakePublisher) Publish(_ context.Context, task *model.Task) error {
+	f.published = append(f.published, task.Data.Log)
+	return nil
+}
+
+func setParams(t *testing.T, p Params) {
+	t.Helper()
+
+	require.Nil(t, SetParams(p))
+	t.Cleanup(func() {
+		require.Nil(t, SetParams(DefaultParams()))
+	})
+}
+
+func TestBuffer(t *testing.T) {
+	setParams(t, Params{MaxBytes: 16})
+
+	buffer := New()
+	for _, line := range []string{"line-1\n", "line-2\n", "line-3\n"} {
+		_, err := buffer.Write([]byte(line))
+		require.Nil(t, err)
+	}
+
+	assert.Equal(t, "... 1 log lines dropped ...\nline-2\nline-3\n", buffer.String())
+}
+
+func TestHookPublisher(t *testing.T) {
+	dir := t.TempDir()
+	setParams(t, Params{Dir: dir})
+
+	defer redact.Track("10.1.2.3", "hunter22")()
+
+	buffer := New()
+
+	l := logrus.New()
+	l.Out = &strings.Builder{}
+	l.Formatter = &logrus.TextFormatter{DisableTimestamp: true}
+	redact.AddHook(l)
+	AddHook(l, buffer)
+
+	logger := l.WithField("bmc", "10.1.2.3")
+	logger.Info("connecting to BMC with hunter22")
+	logger.Debug("not captured at the info level")
+
+	task, err := model.NewTask(uuid.New(), rctypes.FirmwareInstall, &rctypes.FirmwareInstallTaskParameters{
+		Firmwares: []rctypes.Firmware{{Component: "bios", Version: "2.19.6"}},
+	})
+	require.Nil(t, err)
+
+	next := &fakePublisher{}
+	publisher := NewPublisher(next, buffer)
+
+	// the log is published only with the final task state
+	require.Nil(t, publisher.Publish(context.Background(), &task))
+	task.SetState(model.StateFailed)
+	require.Nil(t, publisher.Publish(context.Background(), &task))
+
+	require.Len(t, next.published, 2)
+	assert.Empty(t, next.published[0])
+	assert.Contains(t, next.published[1], "connecting to BMC")
+	assert.NotContains(t, next.published[1], "not captured")
+
+	for _, secret := range []string{"10.1.2.3", "hunter22"} {
+		assert.NotContains(t, next.published[1], secret)
+	}
+
+	require.Nil(t, buffer.WriteFile(task.ID.String()))
+
+	b, err := os.ReadFile(filepath.Join(dir, task.ID.String()+".log"))
+	require.Nil(t, err)
+	assert.Contains(t, string(b), "connecting to BMC")
+	assert.NotContains(t, string(b), "hunter22")
+}
@@ -4,9 +4,11 @@ import (
 	"context"
 
 	"github.com/metal-toolbox/ctrl"
+	"github.com/metal-toolbox/flasher/internal/logging"
 	"github.com/metal-toolbox/flasher/internal/model"
 	"github.com/metal-toolbox/flasher/internal/runner"
 	"github.com/metal-toolbox/flasher/internal/store"
+	"github.com/metal-toolbox/flasher/internal/tasklog"
 	"github.com/metal-toolbox/flasher/internal/version"
 	rctypes "github.com/metal-toolbox/rivets/v2/condition"
 	"github.com/pkg/errors"
@@ -70,18 +72,25 @@ func (h *InbandConditionTaskHandler) HandleTask(
 	publisher ctrl.Publisher,
 ) error {
 	if genericTask == nil {
+		registerEventCounter(false, "ack")
 		return errors.Wrap(errInitTask, "expected a generic Task object, got nil")
 	}
 
 	task, err := model.CopyAsFwInstallTask(genericTask)
 	if err != nil {
+		registerEventCounter(false, "ack")
 		return errors.Wrap(errInitTask, err.Error())
 	}
 
+	registerEventCounter(true, "ack")
+
 	// prepare logger
-	l := logrus.New()
-	l.Formatter = h.logger.Formatter
-	l.Level = h.logger.Level
+	l := logging.Logger(logging.PkgWorker)
+
+	// capture the task log, the hook is added after the redaction hook added to the package logger
+	taskLog := tasklog.New()
+	tasklog.AddHook(l, taskLog)
+
 	hLogger := l.WithFields(
 		logrus.Fields{
 			"conditionID": genericTask.ID.String(),
@@ -90,12 +99,27 @@ func (h *InbandConditionTaskHandler) HandleTask(
 		},
 	)
 
+	// the task log is written once the final task state is published
+	defer func() {
+		if err := taskLog.WriteFile(task.ID.String()); err != nil {
+			hLogger.WithError(err).Warn("task log write error")
+		}
+	}()
+
+	// task updates are coalesced and retried, the final task state is published before the task returns
+	taskPublisher := model.NewCoalescingPublisher(
+		hLogger,
+		tasklog.NewPublisher(model.NewTaskStatusPublisher(hLogger, publisher), taskLog),
+		model.DefaultCoalescingPublisherParams(),
+	)
+	defer taskPublisher.Close()
+
 	// init handler
 	handler := newHandler(
 		model.RunInband,
 		task,
 		h.store,
-		model.NewTaskStatusPublisher(hLogger, publisher),
+		taskPublisher,
 		hLogger,
 	)
 
@@ -0,0 +1,21 @@
+package worker
+
+import (
+	"context"
+	"testing"
+
+	"github.com/metal-toolbox/flasher/internal/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInbandHandleTaskInvalidEvent(t *testing.T) {
+	invalid := metrics.EventsCounter.WithLabelValues("false", "ack")
+	before := testutil.ToFloat64(invalid)
+
+	h := &InbandConditionTaskHandler{}
+	err := h.HandleTask(context.Background(), nil, nil)
+
+	assert.ErrorIs(t, err, errInitTask)
+	assert.Equal(t, before+1, testutil.ToFloat64(invalid))
+}
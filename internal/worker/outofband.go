@@ -2,13 +2,19 @@ package worker
 
 import (
 	"context"
+	"strconv"
 	"sync"
 
+	"github.com/metal-toolbox/flasher/internal/logging"
+	"github.com/metal-toolbox/flasher/internal/metrics"
 	"github.com/metal-toolbox/flasher/internal/model"
+	"github.com/metal-toolbox/flasher/internal/redact"
 	"github.com/metal-toolbox/flasher/internal/runner"
 	"github.com/metal-toolbox/flasher/internal/store"
+	"github.com/metal-toolbox/flasher/internal/tasklog"
 	"github.com/metal-toolbox/flasher/internal/version"
 	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/sirupsen/logrus"
 	"go.opentelemetry.io/otel"
 
@@ -85,6 +91,7 @@ func (h *OobConditionTaskHandler) HandleTask(
 ) error {
 	task, err := model.CopyAsFwInstallTask(genericTask)
 	if err != nil {
+		registerEventCounter(false, "ack")
 		return errors.Wrap(errInitTask, err.Error())
 	}
 
@@ -98,17 +105,27 @@ func (h *OobConditionTaskHandler) HandleTask(
 			"err":          err.Error(),
 		}).Error("asset lookup error")
 
+		registerEventCounter(true, "nack")
+
 		return ctrl.ErrRetryHandler
 	}
 
+	registerEventCounter(true, "ack")
+
 	task.Server = asset
 	task.FacilityCode = h.facilityCode
 	task.WorkerID = h.controllerID
 
+	// redact the BMC credentials, address from logs, traces and the task status
+	defer redact.Track(asset.BMCAddress, asset.BMCPassword)()
+
 	// prepare logger
-	l := logrus.New()
-	l.Formatter = h.logger.Formatter
-	l.Level = h.logger.Level
+	l := logging.Logger(logging.PkgWorker)
+
+	// capture the task log, the hook is added after the redaction hook added to the package logger
+	taskLog := tasklog.New()
+	tasklog.AddHook(l, taskLog)
+
 	hLogger := l.WithFields(
 		logrus.Fields{
 			"conditionID":  task.ID.String(),
@@ -118,12 +135,27 @@ func (h *OobConditionTaskHandler) HandleTask(
 		},
 	)
 
+	// the task log is written once the final task state is published
+	defer func() {
+		if err := taskLog.WriteFile(task.ID.String()); err != nil {
+			hLogger.WithError(err).Warn("task log write error")
+		}
+	}()
+
+	// task updates are coalesced and retried, the final task state is published before the task returns
+	taskPublisher := model.NewCoalescingPublisher(
+		hLogger,
+		tasklog.NewPublisher(model.NewTaskStatusPublisher(hLogger, statusPublisher), taskLog),
+		model.DefaultCoalescingPublisherParams(),
+	)
+	defer taskPublisher.Close()
+
 	// init handler
 	handler := newHandler(
 		model.RunOutofband,
 		task,
 		h.store,
-		model.NewTaskStatusPublisher(hLogger, statusPublisher),
+		taskPublisher,
 		hLogger,
 	)
 
@@ -139,3 +171,15 @@ func (h *OobConditionTaskHandler) HandleTask(
 	hLogger.Info("task for device completed")
 	return nil
 }
+
+// registerEventCounter counts the condition events received,
+// valid is false when the task could not be initialized from the event,
+// response is nack when the event is to be retried.
+func registerEventCounter(valid bool, response string) {
+	metrics.EventsCounter.With(
+		prometheus.Labels{
+			"valid":    strconv.FormatBool(valid),
+			"response": response,
+		},
+	).Inc()
+}
@@ -0,0 +1,119 @@
+package worker
+
+import (
+	"context"
+
+	rctypes "github.com/metal-toolbox/rivets/v2/condition"
+	"github.com/sirupsen/logrus"
+
+	"github.com/metal-toolbox/flasher/internal/model"
+	"github.com/metal-toolbox/flasher/internal/runner"
+	"github.com/metal-toolbox/flasher/internal/store"
+)
+
+// Reasons recorded for each firmware when planning install actions.
+const (
+	SkipEqualVersion          = "component firmware version equal"
+	SkipNotInInventory        = "component not found in inventory"
+	SkipEmptyVersion          = "component firmware version returned empty"
+	SkipInstallMethodMismatch = "firmware install method does not match the run mode"
+	QueuedVersionDiffers      = "firmware queued for install"
+	QueuedForceInstall        = "firmware queued for install, force install set"
+)
+
+// PlanDecision is the install decision made for a firmware when planning actions.
+type PlanDecision struct {
+	Component      string `json:"component" yaml:"component"`
+	Version        string `json:"version" yaml:"version"`
+	CurrentVersion string `json:"current_version" yaml:"current_version"`
+	Queued         bool   `json:"queued" yaml:"queued"`
+	Reason         string `json:"reason" yaml:"reason"`
+}
+
+// PlanAction is an install action composed when planning, with the steps it would execute.
+type PlanAction struct {
+	ID            string   `json:"id" yaml:"id"`
+	Component     string   `json:"component" yaml:"component"`
+	Version       string   `json:"version" yaml:"version"`
+	InstallMethod string   `json:"install_method" yaml:"install_method"`
+	Steps         []string `json:"steps" yaml:"steps"`
+}
+
+// Plan is the result of planning a firmware install task without executing it.
+type Plan struct {
+	Decisions []*PlanDecision `json:"decisions" yaml:"decisions"`
+	Actions   []*PlanAction   `json:"actions" yaml:"actions"`
+}
+
+func (t *handler) decide(fw *rctypes.Firmware, currentVersion, reason string) {
+	t.decisions = append(t.decisions, &PlanDecision{
+		Component:      fw.Component,
+		Version:        fw.Version,
+		CurrentVersion: currentVersion,
+		Queued:         reason == QueuedVersionDiffers || reason == QueuedForceInstall,
+		Reason:         reason,
+	})
+}
+
+// nopPublisher drops task status updates, plans are not published.
+type nopPublisher struct{}
+
+func (nopPublisher) Publish(context.Context, *model.Task) error { return nil }
+
+// PlanTask runs the query and plan phases of the task handler the worker runs,
+// returning the firmware install decisions and the composed actions.
+//
+// Nothing is installed, the device is queried for its inventory and install steps.
+// The deviceQueryor is optional, when nil one is initialized for the run mode.
+func PlanTask(
+	ctx context.Context,
+	mode model.RunMode,
+	task *model.Task,
+	repository store.Repository,
+	deviceQueryor any,
+	logger *logrus.Entry,
+) (*Plan, error) {
+	h := &handler{
+		mode: mode,
+		TaskHandlerContext: &runner.TaskHandlerContext{
+			Task:          task,
+			Publisher:     nopPublisher{},
+			Store:         repository,
+			Logger:        logger,
+			DeviceQueryor: deviceQueryor,
+		},
+	}
+
+	if err := h.Initialize(ctx); err != nil {
+		return nil, err
+	}
+
+	// closes the BMC session when planning out-of-band
+	defer h.OnSuccess(ctx, task)
+
+	if err := h.Query(ctx); err != nil {
+		return nil, err
+	}
+
+	if err := h.PlanActions(ctx); err != nil {
+		return nil, err
+	}
+
+	plan := &Plan{Decisions: h.decisions}
+	for _, action := range task.Data.ActionsPlanned {
+		planned := &PlanAction{
+			ID:            action.ID,
+			Component:     action.Firmware.Component,
+			Version:       action.Firmware.Version,
+			InstallMethod: string(action.InstallMethod),
+		}
+
+		for _, step := range action.Steps {
+			planned.Steps = append(planned.Steps, string(step.Name))
+		}
+
+		plan.Actions = append(plan.Actions, planned)
+	}
+
+	return plan, nil
+}
@@ -0,0 +1,66 @@
+package worker
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	common "github.com/metal-toolbox/bmc-common"
+	bconsts "github.com/metal-toolbox/bmclib/constants"
+	rctypes "github.com/metal-toolbox/rivets/v2/condition"
+	rtypes "github.com/metal-toolbox/rivets/v2/types"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/metal-toolbox/flasher/internal/device"
+	"github.com/metal-toolbox/flasher/internal/model"
+)
+
+func TestPlanTask_Outofband(t *testing.T) {
+	dev := common.NewDevice()
+	dev.Vendor = "Dell"
+	dev.Model = "PowerEdge R6515"
+	dev.BIOS = &common.BIOS{Common: common.Common{Firmware: &common.Firmware{Installed: "2.6.6"}}}
+	dev.BMC = &common.BMC{Common: common.Common{Firmware: &common.Firmware{Installed: "5.10.00.00"}}}
+
+	dq := device.NewMockOutofbandQueryor(t)
+	dq.EXPECT().Open(mock.Anything).Return(nil)
+	dq.EXPECT().Inventory(mock.Anything).Return(&dev, nil)
+	dq.EXPECT().FirmwareInstallSteps(mock.Anything, "bios").
+		Return([]bconsts.FirmwareInstallStep{
+			bconsts.FirmwareInstallStepUploadInitiateInstall,
+			bconsts.FirmwareInstallStepInstallStatus,
+		}, nil)
+	dq.EXPECT().Close(mock.Anything).Return(nil)
+
+	task, err := model.NewTask(uuid.New(), rctypes.FirmwareInstall, &rctypes.FirmwareInstallTaskParameters{
+		AssetID: uuid.New(),
+		Firmwares: []rctypes.Firmware{
+			{Component: "bios", Version: "2.19.6"},
+			{Component: "bmc", Version: "5.10.00.00"},
+			{Component: "drive", Version: "DL6R"},
+			{Component: "nic", Version: "1.2.3", InstallInband: true},
+		},
+	})
+	require.Nil(t, err)
+
+	task.Server = &rtypes.Server{}
+
+	plan, err := PlanTask(context.Background(), model.RunOutofband, &task, nil, dq, logrus.NewEntry(logrus.New()))
+	require.Nil(t, err)
+
+	want := []*PlanDecision{
+		{Component: "nic", Version: "1.2.3", Reason: SkipInstallMethodMismatch},
+		{Component: "bios", Version: "2.19.6", CurrentVersion: "2.6.6", Queued: true, Reason: QueuedVersionDiffers},
+		{Component: "bmc", Version: "5.10.00.00", CurrentVersion: "5.10.00.00", Reason: SkipEqualVersion},
+		{Component: "drive", Version: "DL6R", Reason: SkipNotInInventory},
+	}
+
+	assert.Equal(t, want, plan.Decisions)
+	require.Len(t, plan.Actions, 1)
+	assert.Equal(t, "bios", plan.Actions[0].Component)
+	assert.Equal(t, string(model.InstallMethodOutofband), plan.Actions[0].InstallMethod)
+	assert.NotEmpty(t, plan.Actions[0].Steps)
+}
@@ -3,7 +3,6 @@ package worker
 import (
 	"context"
 	"fmt"
-	"sort"
 	"strings"
 
 	common "github.com/metal-toolbox/bmc-common"
@@ -32,6 +31,8 @@ var (
 type handler struct {
 	mode    model.RunMode
 	resumed bool
+	// decisions records the install decision for each firmware when planning actions.
+	decisions []*PlanDecision
 	*runner.TaskHandlerContext
 }
 
@@ -113,13 +114,13 @@ func (t handler) inventoryOutofband(ctx context.Context) (*common.Device, error)
 		return nil, err
 	}
 
-	t.Task.Status.Append("connecting to device BMC")
+	t.Task.AppendStatus("connecting to device BMC")
 	t.Publish(ctx)
 	if err := t.DeviceQueryor.(device.OutofbandQueryor).Open(ctx); err != nil {
 		return nil, err
 	}
 
-	t.Task.Status.Append("collecting inventory from device BMC")
+	t.Task.AppendStatus("collecting inventory from device BMC")
 	t.Publish(ctx)
 
 	deviceCommon, err := t.DeviceQueryor.(device.OutofbandQueryor).Inventory(ctx)
@@ -131,7 +132,7 @@ func (t handler) inventoryOutofband(ctx context.Context) (*common.Device, error)
 }
 
 func (t handler) inventoryInband(ctx context.Context) (*common.Device, error) {
-	t.Task.Status.Append("collecting inventory from server")
+	t.Task.AppendStatus("collecting inventory from server")
 	t.Publish(ctx)
 
 	deviceCommon, err := t.DeviceQueryor.(device.InbandQueryor).Inventory(ctx)
@@ -183,7 +184,7 @@ func (t *handler) planFromFirmwareSlice(ctx context.Context) error {
 func (t *handler) planFromFirmwareSet(ctx context.Context) error {
 	applicable, err := t.Store.FirmwareSetByID(ctx, t.Task.Parameters.FirmwareSetID)
 	if err != nil {
-		return errors.Wrap(errTaskPlanActions, err.Error())
+		return model.WrapFailure(errTaskPlanActions, err)
 	}
 
 	if len(applicable) == 0 {
@@ -235,11 +236,15 @@ func (t *handler) planInstallActions(ctx context.Context, firmwares []*rctypes.F
 	for _, fw := range firmwares {
 		if t.mode == model.RunOutofband && !fw.InstallInband {
 			toInstall = append(toInstall, fw)
+			continue
 		}
 
 		if t.mode == model.RunInband && fw.InstallInband {
 			toInstall = append(toInstall, fw)
+			continue
 		}
+
+		t.decide(fw, "", SkipInstallMethodMismatch)
 	}
 
 	t.Logger.WithFields(logrus.Fields{
@@ -250,11 +255,15 @@ func (t *handler) planInstallActions(ctx context.Context, firmwares []*rctypes.F
 	// purge any firmware that are already installed
 	if !t.Task.Parameters.ForceInstall {
 		toInstall = t.removeFirmwareAlreadyAtDesiredVersion(toInstall)
+	} else {
+		for _, fw := range toInstall {
+			t.decide(fw, "", QueuedForceInstall)
+		}
 	}
 
 	if len(toInstall) == 0 {
 		info := fmt.Sprintf("no %s firmware installs required", t.mode)
-		t.Task.Status.Append(info)
+		t.Task.AppendStatus(info)
 		t.Publish(ctx)
 
 		return nil, nil
@@ -293,7 +302,7 @@ func (t *handler) planInstallActions(ctx context.Context, firmwares []*rctypes.F
 
 		action, err := actionHander.ComposeAction(ctx, actionCtx)
 		if err != nil {
-			return nil, errors.Wrap(errTaskPlanActions, err.Error())
+			return nil, model.WrapFailure(errTaskPlanActions, err)
 		}
 
 		action.SetID(t.Task.ID.String(), firmware.Component, idx)
@@ -308,7 +317,7 @@ func (t *handler) planInstallActions(ctx context.Context, firmwares []*rctypes.F
 		info = fmt.Sprintf("no %s firmware installs required", t.mode)
 	}
 
-	t.Task.Status.Append(info)
+	t.Task.AppendStatus(info)
 	t.Publish(ctx)
 	t.Logger.Info(info)
 
@@ -316,11 +325,7 @@ func (t *handler) planInstallActions(ctx context.Context, firmwares []*rctypes.F
 }
 
 func (t *handler) sortFirmwareByInstallOrder(firmwares []*rctypes.Firmware) {
-	sort.Slice(firmwares, func(i, j int) bool {
-		slugi := strings.ToLower(firmwares[i].Component)
-		slugj := strings.ToLower(firmwares[j].Component)
-		return model.FirmwareInstallOrder[slugi] < model.FirmwareInstallOrder[slugj]
-	})
+	model.SortFirmwareByInstallOrder(firmwares)
 }
 
 // returns a list of firmware applicable and a list of causes for firmwares that were removed from the install list.
@@ -356,10 +361,19 @@ func (t *handler) removeFirmwareAlreadyAtDesiredVersion(fws []*rctypes.Firmware)
 	for _, fw := range fws {
 		currentVersion, ok := invMap[strings.ToLower(fw.Component)]
 
+		switch {
+		case !ok:
+			t.Logger.WithFields(logrus.Fields{
+				"component": fw.Component,
+			}).Warn(SkipNotInInventory)
+
+			t.decide(fw, "", SkipNotInInventory)
+			t.Task.AppendStatus(fmtCause(fw.Component, SkipNotInInventory, "", ""))
+
 		// skip install if current firmware version was not identified
-		if currentVersion == "" && !t.Task.Parameters.ForceInstall {
+		case currentVersion == "":
 			info := "Current firmware version returned empty, skipped install, use force to override"
-			t.Task.Status.Append(
+			t.Task.AppendStatus(
 				fmtCause(
 					fw.Component,
 					info,
@@ -370,40 +384,31 @@ func (t *handler) removeFirmwareAlreadyAtDesiredVersion(fws []*rctypes.Firmware)
 
 			t.Logger.WithFields(logrus.Fields{
 				"component": fw.Component,
-			}).Warn()
-
-			continue
-		}
-
-		switch {
-		case !ok:
-			cause := "component not found in inventory"
-			t.Logger.WithFields(logrus.Fields{
-				"component": fw.Component,
-			}).Warn(cause)
+			}).Warn(SkipEmptyVersion)
 
-			t.Task.Status.Append(fmtCause(fw.Component, cause, "", ""))
+			t.decide(fw, currentVersion, SkipEmptyVersion)
 
 		case strings.EqualFold(currentVersion, fw.Version):
-			cause := "component firmware version equal"
 			t.Logger.WithFields(logrus.Fields{
 				"component": fw.Component,
 				"version":   fw.Version,
-			}).Debug(cause)
+			}).Debug(SkipEqualVersion)
 
-			t.Task.Status.Append(fmtCause(fw.Component, cause, currentVersion, fw.Version))
+			t.decide(fw, currentVersion, SkipEqualVersion)
+			t.Task.AppendStatus(fmtCause(fw.Component, SkipEqualVersion, currentVersion, fw.Version))
 
 		default:
 			t.Logger.WithFields(logrus.Fields{
 				"component":         fw.Component,
 				"installed.version": currentVersion,
 				"mandated.version":  fw.Version,
-			}).Debug("firmware queued for install")
+			}).Debug(QueuedVersionDiffers)
 
 			toInstall = append(toInstall, fw)
 
-			t.Task.Status.Append(
-				fmtCause(fw.Component, "firmware queued for install", currentVersion, fw.Version),
+			t.decide(fw, currentVersion, QueuedVersionDiffers)
+			t.Task.AppendStatus(
+				fmtCause(fw.Component, QueuedVersionDiffers, currentVersion, fw.Version),
 			)
 		}
 	}